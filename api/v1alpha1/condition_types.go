@@ -5,6 +5,33 @@
 package v1alpha1
 
 const (
+	// RepositoryReadyCondition indicates the readiness of the git-controller Repository of the project.
+	RepositoryReadyCondition string = "RepositoryReady"
+
+	// SourceReadyCondition indicates the readiness of the Flux GitRepository source of the project.
+	SourceReadyCondition string = "SourceReady"
+
+	// KustomizationsReadyCondition indicates the readiness of the Flux Kustomizations of the project.
+	KustomizationsReadyCondition string = "KustomizationsReady"
+
+	// CertificateReadyCondition indicates the readiness of the registry certificate of the project.
+	CertificateReadyCondition string = "CertificateReady"
+
+	// DeletionBlockedCondition indicates that the deletion of the project is blocked.
+	DeletionBlockedCondition string = "DeletionBlocked"
+
+	// ForeignChangesCondition indicates that project resources were recreated or changed by someone else.
+	ForeignChangesCondition string = "ForeignChanges"
+
+	// TerminatingCondition indicates the progress of deleting the resources of a deleted project.
+	TerminatingCondition string = "Terminating"
+)
+
+const (
+	// WaitingOnResourcesReason indicates that the project was requeued to wait for its resources to be created.
+	//
+	// Deprecated: The project reconciler builds the inventory from the applied objects and no longer requeues
+	// to wait for them.
 	WaitingOnResourcesReason string = "WaitingOnResources"
 
 	// NamespaceCreateOrUpdateFailedReason indicates that the project namespace could not be reconciled.
@@ -16,6 +43,18 @@ const (
 	// RBACCreateOrUpdateFailedReason indicates that the project cluster role could not be reconciled.
 	RBACCreateOrUpdateFailedReason string = "RBACCreateOrUpdateFailed" //nolint:gosec // not a cred
 
+	// RBACRejectedReason indicates that the additional RBAC rules of the project are not permitted by the allow-list.
+	RBACRejectedReason string = "RBACRejected"
+
+	// ResourceQuotaCreateOrUpdateFailedReason indicates that the project resource quota could not be reconciled.
+	ResourceQuotaCreateOrUpdateFailedReason string = "ResourceQuotaCreateOrUpdateFailed"
+
+	// LimitRangeCreateOrUpdateFailedReason indicates that the project limit range could not be reconciled.
+	LimitRangeCreateOrUpdateFailedReason string = "LimitRangeCreateOrUpdateFailed"
+
+	// NetworkPolicyCreateOrUpdateFailedReason indicates that the project network policies could not be reconciled.
+	NetworkPolicyCreateOrUpdateFailedReason string = "NetworkPolicyCreateOrUpdateFailed"
+
 	// CertificateCreateOrUpdateFailedReason indicates that the project certificate could not be reconciled.
 	CertificateCreateOrUpdateFailedReason string = "CertificateCreateOrUpdateFailed"
 
@@ -28,6 +67,35 @@ const (
 	// FluxKustomizationsCreateOrUpdateFailedReason indicates that the project Flux Kustomizations could not be reconciled.
 	FluxKustomizationsCreateOrUpdateFailedReason string = "FluxKustomizationsCreateOrUpdateFailed"
 
+	// InventoryMigrationFailedReason indicates that the inventory entries could not be moved to the API versions
+	// served by the cluster.
+	InventoryMigrationFailedReason string = "InventoryMigrationFailed"
+
+	// ClusterRoleNotFoundReason indicates that the ClusterRole bound in every project namespace does not exist.
+	ClusterRoleNotFoundReason string = "ClusterRoleNotFound"
+
+	// ClusterIssuerNotFoundReason indicates that the ClusterIssuer of the project certificate does not exist.
+	ClusterIssuerNotFoundReason string = "ClusterIssuerNotFound"
+
+	// ExistingResourceConflictReason indicates that a project resource already exists and may not be adopted
+	// according to the existing repository policy of the project.
+	ExistingResourceConflictReason string = "ExistingResourceConflict"
+
+	// NamespaceOwnershipConflictReason indicates that the project namespace belongs to another project.
+	NamespaceOwnershipConflictReason string = "NamespaceOwnershipConflict"
+
+	// DeletionProtectedReason indicates that the project is protected from deletion by an annotation.
+	DeletionProtectedReason string = "DeletionProtected"
+
+	// ResourcesInUseReason indicates that the deletion of the project is blocked by resources in the project namespace.
+	ResourcesInUseReason string = "ResourcesInUse"
+
+	// ForeignRecreationReason indicates that project resources were deleted and recreated by someone else.
+	ForeignRecreationReason string = "ForeignRecreation"
+
+	// UnexpectedChangeReason indicates that the spec of project resources was changed by someone else.
+	UnexpectedChangeReason string = "UnexpectedChange"
+
 	// ReconciliationFailedReason represents the fact that the reconciliation failed.
 	ReconciliationFailedReason string = "ReconciliationFailed"
 )
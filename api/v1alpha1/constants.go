@@ -8,6 +8,10 @@ const (
 	// ProjectKey contains the name of the project for this namespace.
 	// This key is used to look up the Project that belongs to it.
 	ProjectKey = "mpas.ocm.system/project"
+
+	// ProjectNamespaceKey contains the namespace of the project that owns a resource.
+	// Together with ProjectKey it identifies the Project that created the resource.
+	ProjectNamespaceKey = "mpas.ocm.system/project-namespace"
 )
 
 const (
@@ -15,3 +19,33 @@ const (
 	// in the service account of the project.
 	ManagedMPASSecretAnnotationKey = "mpas.ocm.system/secret.dockerconfig" //nolint:gosec // not a cred
 )
+
+const (
+	// DeletionProtectionAnnotationKey blocks the deletion of a Project while it is set to "true".
+	DeletionProtectionAnnotationKey = "mpas.ocm.system/deletion-protection"
+
+	// ForceDeleteAnnotationKey deletes a Project that is blocked by resources in use while it is set to "true".
+	ForceDeleteAnnotationKey = "mpas.ocm.system/force-delete"
+)
+
+const (
+	// PruneAnnotationKey disables the deletion of a project resource by prune and on Project deletion
+	// if it is set to PruneDisabledValue. The resource is orphaned instead.
+	PruneAnnotationKey = "mpas.ocm.system/prune"
+
+	// PruneDisabledValue is the value of PruneAnnotationKey that disables pruning.
+	PruneDisabledValue = "disabled"
+)
+
+const (
+	// PropagateSecretAnnotationKey copies a Secret in the namespace of the controller into the namespaces of the
+	// matching Projects, where it is added to the project ServiceAccount. The value is either PropagateToAllProjects
+	// or a label selector for Projects.
+	PropagateSecretAnnotationKey = "mpas.ocm.system/secret.propagate" //nolint:gosec // not a cred
+
+	// PropagateToAllProjects is the value of PropagateSecretAnnotationKey that matches all Projects.
+	PropagateToAllProjects = "*"
+
+	// PropagatedFromAnnotationKey contains the <namespace>/<name> of the Secret a propagated Secret is copied from.
+	PropagatedFromAnnotationKey = "mpas.ocm.system/secret.propagated-from" //nolint:gosec // not a cred
+)
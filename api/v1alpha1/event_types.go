@@ -7,4 +7,14 @@ const (
 	AddServiceAccountImagePullSecretsReason = "ImagePullSecretAdded"
 	// RemoveServiceAccountImagePullSecretsReason defines the reason why the update occurred.
 	RemoveServiceAccountImagePullSecretsReason = "ImagePullSecretRemoved"
+	// ChangesAppliedReason is used when project resources were created or configured.
+	ChangesAppliedReason = "ChangesApplied"
+	// DriftDetectedReason is used when project resources have drifted from their desired state.
+	DriftDetectedReason = "DriftDetected"
+	// PruneSkippedReason is used when project resources are kept because pruning is disabled for them.
+	PruneSkippedReason = "PruneSkipped"
+	// InventoryMigratedReason is used when inventory entries are moved to the API version served by the cluster.
+	InventoryMigratedReason = "InventoryMigrated"
+	// SecretPropagationFailedReason is used when a Secret can't be propagated into project namespaces.
+	SecretPropagationFailedReason = "SecretPropagationFailed"
 )
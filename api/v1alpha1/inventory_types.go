@@ -4,11 +4,33 @@
 
 package v1alpha1
 
+import "k8s.io/apimachinery/pkg/types"
+
 // ResourceInventory contains a list of Kubernetes resource object references
 // that have been created by the project.
 type ResourceInventory struct {
 	// Entries of Kubernetes resource object references.
 	Entries []ResourceRef `json:"entries"`
+
+	// ConfigMapRef references the ConfigMap in the namespace of the Project that stores the entries
+	// if the inventory is too large to be kept in the status. Entries is empty in that case.
+	// +optional
+	ConfigMapRef *InventoryConfigMapReference `json:"configMapRef,omitempty"`
+}
+
+// InventoryConfigMapReference references the ConfigMap that stores the compressed entries of an inventory.
+type InventoryConfigMapReference struct {
+	// Name of the ConfigMap.
+	// +required
+	Name string `json:"name"`
+
+	// Digest is the SHA-256 digest of the stored entries.
+	// +required
+	Digest string `json:"digest"`
+
+	// Count is the number of stored entries.
+	// +required
+	Count int `json:"count"`
 }
 
 // ResourceRef contains the information required to locate a resource within a cluster.
@@ -19,4 +41,17 @@ type ResourceRef struct {
 
 	// Version is the API version of the Kubernetes resource object's kind.
 	Version string `json:"v"`
+
+	// UID is the unique identifier of the Kubernetes resource object, which changes if the object is recreated.
+	// +optional
+	UID types.UID `json:"uid,omitempty"`
+
+	// Checksum is the SHA-256 checksum of the spec of the Kubernetes resource object.
+	// +optional
+	Checksum string `json:"checksum,omitempty"`
+
+	// AppliedChecksum is the SHA-256 checksum of the desired state of the Kubernetes resource object
+	// that was last applied by the controller.
+	// +optional
+	AppliedChecksum string `json:"appliedChecksum,omitempty"`
 }
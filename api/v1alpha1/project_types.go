@@ -9,6 +9,9 @@ import (
 	"strings"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 
@@ -20,8 +23,64 @@ const (
 	ProjectFinalizer = "finalizers.mpas.ocm.software"
 )
 
-// ExistingRepositoryPolicy defines what to do in case a requested repository already exists.
-type ExistingRepositoryPolicy string
+// ExistingResourcePolicy defines what to do in case a project resource already exists in the cluster.
+// +kubebuilder:validation:Enum=Fail;Adopt;AdoptIfLabelled
+type ExistingResourcePolicy string
+
+const (
+	// ExistingResourcePolicyFail stalls the Project if a project resource already exists
+	// and is not owned by the Project.
+	ExistingResourcePolicyFail ExistingResourcePolicy = "Fail"
+
+	// ExistingResourcePolicyAdopt adopts any project resource that already exists.
+	ExistingResourcePolicyAdopt ExistingResourcePolicy = "Adopt"
+
+	// ExistingResourcePolicyAdoptIfLabelled adopts a project resource that already exists only if
+	// it carries the ProjectKey label with the name of the Project.
+	ExistingResourcePolicyAdoptIfLabelled ExistingResourcePolicy = "AdoptIfLabelled"
+)
+
+// DriftPolicy defines what to do if a project resource has drifted from its desired state.
+// +kubebuilder:validation:Enum=Correct;Report
+type DriftPolicy string
+
+const (
+	// DriftPolicyCorrect reverts drifted project resources to their desired state and reports the drift.
+	DriftPolicyCorrect DriftPolicy = "Correct"
+
+	// DriftPolicyReport only reports drifted project resources without correcting them. Deleted project
+	// resources are recreated regardless of the policy.
+	DriftPolicyReport DriftPolicy = "Report"
+)
+
+// DeletionPolicy defines what happens to a project resource when the Project is deleted.
+// +kubebuilder:validation:Enum=Delete;Orphan
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete deletes the project resource together with the Project.
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+
+	// DeletionPolicyOrphan keeps the project resource and removes the owner reference to the Project.
+	DeletionPolicyOrphan DeletionPolicy = "Orphan"
+)
+
+// DeletionPolicySpec defines what happens to the project resources when the Project is deleted.
+type DeletionPolicySpec struct {
+	// Default applies to the kinds that have no policy in Kinds. If not set, the project resources are
+	// deleted if prune is enabled and orphaned otherwise.
+	// +optional
+	Default DeletionPolicy `json:"default,omitempty"`
+	// Kinds maps the kind of project resources, like Namespace, Repository, GitRepository or Kustomization,
+	// to their deletion policy. Orphaned resources inside a deleted project namespace are deleted with it.
+	// +optional
+	Kinds map[string]DeletionPolicy `json:"kinds,omitempty"`
+	// BlockWhileInUse blocks the deletion of the Project while resources of the kinds configured in the
+	// controller, like ProductDeployments, exist in the project namespace. It has no effect if the project
+	// namespace is orphaned.
+	// +optional
+	BlockWhileInUse bool `json:"blockWhileInUse,omitempty"`
+}
 
 // ProjectSpec defines the desired state of Project.
 type ProjectSpec struct {
@@ -35,6 +94,116 @@ type ProjectSpec struct {
 	Prune bool `json:"prune,omitempty"`
 	// +optional
 	Interval metav1.Duration `json:"interval,omitempty"`
+	// Suspend tells the controller to suspend the reconciliation of this Project. The suspension is
+	// propagated to the Flux GitRepository and Kustomizations of the Project.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+	// ExistingResourcePolicy defines what to do if the project namespace, Repository or GitRepository
+	// already exists in the cluster and is not owned by this Project. It is independent of
+	// Git.ExistingRepositoryPolicy, which is passed on to the git-controller and only applies to the
+	// repository at the Git provider.
+	// +optional
+	// +kubebuilder:default=Adopt
+	ExistingResourcePolicy ExistingResourcePolicy `json:"existingResourcePolicy,omitempty"`
+	// Quota defines the ResourceQuota that is created in the project namespace.
+	// +optional
+	Quota *corev1.ResourceQuotaSpec `json:"quota,omitempty"`
+	// Limits defines the LimitRange that is created in the project namespace.
+	// +optional
+	Limits *corev1.LimitRangeSpec `json:"limits,omitempty"`
+	// NetworkPolicy defines the NetworkPolicies that are created in the project namespace.
+	// +optional
+	NetworkPolicy *NetworkPolicySpec `json:"networkPolicy,omitempty"`
+	// Members contains the users, groups and service accounts that are granted access to the project namespace.
+	// +optional
+	Members []ProjectMember `json:"members,omitempty"`
+	// RBAC defines additional permissions of the project ServiceAccount.
+	// +optional
+	RBAC *RBACSpec `json:"rbac,omitempty"`
+	// DriftPolicy defines what to do if a project resource was changed outside of the controller.
+	// +optional
+	// +kubebuilder:default=Correct
+	DriftPolicy DriftPolicy `json:"driftPolicy,omitempty"`
+	// DeletionPolicy defines per kind which project resources are deleted together with the Project.
+	// +optional
+	DeletionPolicy *DeletionPolicySpec `json:"deletionPolicy,omitempty"`
+}
+
+// RBACSpec defines additional permissions of the project ServiceAccount.
+type RBACSpec struct {
+	// AdditionalRules are added to the project Role. The API groups, resources and verbs of the
+	// rules must be permitted by the RBAC allow-list of the controller.
+	// +optional
+	AdditionalRules []rbacv1.PolicyRule `json:"additionalRules,omitempty"`
+}
+
+// ProjectRole defines the level of access a member has to the project namespace.
+// +kubebuilder:validation:Enum=admin;editor;viewer
+type ProjectRole string
+
+const (
+	// ProjectRoleAdmin grants full access to the project resources and RBAC of the project namespace.
+	ProjectRoleAdmin ProjectRole = "admin"
+
+	// ProjectRoleEditor grants full access to the project resources of the project namespace.
+	ProjectRoleEditor ProjectRole = "editor"
+
+	// ProjectRoleViewer grants read access to the project resources of the project namespace.
+	ProjectRoleViewer ProjectRole = "viewer"
+)
+
+// ProjectMember defines a subject that is granted access to the project namespace.
+type ProjectMember struct {
+	// Kind of the subject.
+	// +required
+	// +kubebuilder:validation:Enum=User;Group;ServiceAccount
+	Kind string `json:"kind"`
+
+	// Name of the subject.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace of the ServiceAccount. Defaults to the project namespace.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Role defines the level of access of the subject.
+	// +required
+	Role ProjectRole `json:"role"`
+}
+
+// NetworkPolicyMode defines which NetworkPolicies are created in the project namespace.
+// +kubebuilder:validation:Enum=Disabled;Default;Custom
+type NetworkPolicyMode string
+
+const (
+	// NetworkPolicyModeDisabled does not create any NetworkPolicies.
+	NetworkPolicyModeDisabled NetworkPolicyMode = "Disabled"
+
+	// NetworkPolicyModeDefault denies all traffic except DNS, traffic within the project namespace,
+	// egress to the internal OCM registry and traffic from and to the MPAS system namespace.
+	NetworkPolicyModeDefault NetworkPolicyMode = "Default"
+
+	// NetworkPolicyModeCustom allows the same traffic as NetworkPolicyModeDefault and additionally
+	// traffic from and to the configured ingress and egress peers.
+	NetworkPolicyModeCustom NetworkPolicyMode = "Custom"
+)
+
+// NetworkPolicySpec defines the NetworkPolicies of a project namespace.
+type NetworkPolicySpec struct {
+	// Mode defines which NetworkPolicies are created.
+	// +optional
+	// +kubebuilder:default=Default
+	Mode NetworkPolicyMode `json:"mode,omitempty"`
+
+	// Ingress contains the peers that are allowed to connect to the project namespace in Custom mode.
+	// +optional
+	Ingress []networkingv1.NetworkPolicyPeer `json:"ingress,omitempty"`
+
+	// Egress contains the peers that the project namespace is allowed to connect to in Custom mode.
+	// +optional
+	Egress []networkingv1.NetworkPolicyPeer `json:"egress,omitempty"`
 }
 
 type FluxSpec struct {
@@ -42,6 +211,50 @@ type FluxSpec struct {
 	// +kubebuilder:validation:Type=string
 	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m|h))+$"
 	Interval metav1.Duration `json:"interval,omitempty"`
+
+	// Kustomizations defines the Flux Kustomizations that are created for the project repository.
+	// If empty, a Kustomization is created for each of the subscriptions, targets, products and generators folders.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Kustomizations []FluxKustomization `json:"kustomizations,omitempty"`
+}
+
+// FluxKustomization defines a Flux Kustomization for a folder in the project repository.
+type FluxKustomization struct {
+	// Name is appended to the prefixed project name to form the name of the Kustomization.
+	// +required
+	// +kubebuilder:validation:Pattern="^[a-z0-9]([-a-z0-9]*[a-z0-9])?$"
+	Name string `json:"name"`
+
+	// Path is the path of the folder in the project repository. Defaults to the name.
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// Interval overrides the Flux interval of the project for this Kustomization.
+	// +optional
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m|h))+$"
+	Interval *metav1.Duration `json:"interval,omitempty"`
+
+	// Prune enables garbage collection of the objects that have been removed from the folder.
+	// +optional
+	Prune bool `json:"prune,omitempty"`
+
+	// DependsOn contains the names of other Kustomizations of this project that must be ready
+	// before this Kustomization is applied.
+	// +optional
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+// DefaultFluxKustomizations returns the Kustomizations that are created if the Project does not define any.
+func DefaultFluxKustomizations() []FluxKustomization {
+	return []FluxKustomization{
+		{Name: "subscriptions", Path: "subscriptions"},
+		{Name: "targets", Path: "targets"},
+		{Name: "products", Path: "products"},
+		{Name: "generators", Path: "generators"},
+	}
 }
 
 // CommitTemplate defines the default commit template for a project if one is not provided in the spec.
@@ -68,9 +281,37 @@ type ProjectStatus struct {
 	// RepositoryRef contains the reference to the repository resource that has been created by the project controller.
 	// +optional
 	RepositoryRef *meta.NamespacedObjectReference `json:"repositoryRef,omitempty"`
+
+	// LastDrift describes the project resources that were last found to have drifted from their desired state.
+	// +optional
+	LastDrift *DriftReport `json:"lastDrift,omitempty"`
+
+	// PruneSkipped contains the resources that were last kept instead of being deleted, because pruning
+	// is disabled for them, in the format <kind>/<namespace>/<name>.
+	// +optional
+	PruneSkipped []string `json:"pruneSkipped,omitempty"`
+
+	meta.ReconcileRequestStatus `json:",inline"`
+}
+
+// DriftReport describes project resources that have drifted from their desired state.
+type DriftReport struct {
+	// DetectedAt is the time the drift was detected.
+	// +required
+	DetectedAt metav1.Time `json:"detectedAt"`
+
+	// Resources contains the drifted resources in the format <kind>/<namespace>/<name>.
+	// +required
+	Resources []string `json:"resources"`
+
+	// Corrected is true if the drifted resources were reverted to their desired state.
+	// +optional
+	Corrected bool `json:"corrected,omitempty"`
 }
 
 // GetServiceAccountNamespacedName returns the service account namespace name from the inventory.
+//
+// Deprecated: Use inventory.ServiceAccount, which parses the inventory entries into object metadata.
 func (in *Project) GetServiceAccountNamespacedName() (types.NamespacedName, error) {
 	// Entry ID: <namespace>_<name>_<group>_<kind>. Just look for a postfix of gitrepository
 	if in.Status.Inventory == nil {
@@ -105,6 +346,7 @@ func (in *Project) GetServiceAccountNamespacedName() (types.NamespacedName, erro
 // +kubebuilder:resource:shortName=proj
 // +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description=""
 // +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].message",description=""
+// +kubebuilder:printcolumn:name="Suspended",type="boolean",JSONPath=".spec.suspend",description=""
 
 // Project is the Schema for the projects API.
 type Project struct {
@@ -130,6 +372,43 @@ func (in *Project) GetRequeueAfter() time.Duration {
 	return in.Spec.Interval.Duration
 }
 
+// GetFluxKustomizations returns the Flux Kustomizations of the Project or the defaults if none are set.
+func (in *Project) GetFluxKustomizations() []FluxKustomization {
+	if len(in.Spec.Flux.Kustomizations) == 0 {
+		return DefaultFluxKustomizations()
+	}
+
+	return in.Spec.Flux.Kustomizations
+}
+
+// GetNetworkPolicyMode returns the NetworkPolicy mode of the Project, which defaults to NetworkPolicyModeDefault.
+func (in *Project) GetNetworkPolicyMode() NetworkPolicyMode {
+	if in.Spec.NetworkPolicy == nil || in.Spec.NetworkPolicy.Mode == "" {
+		return NetworkPolicyModeDefault
+	}
+
+	return in.Spec.NetworkPolicy.Mode
+}
+
+// GetDeletionPolicy returns the deletion policy of the project resources of the given kind.
+func (in *Project) GetDeletionPolicy(kind string) DeletionPolicy {
+	if in.Spec.DeletionPolicy != nil {
+		if policy, ok := in.Spec.DeletionPolicy.Kinds[kind]; ok {
+			return policy
+		}
+
+		if in.Spec.DeletionPolicy.Default != "" {
+			return in.Spec.DeletionPolicy.Default
+		}
+	}
+
+	if in.Spec.Prune {
+		return DeletionPolicyDelete
+	}
+
+	return DeletionPolicyOrphan
+}
+
 func (in *Project) GetNameWithPrefix(prefix string) string {
 	return prefix + "-" + in.Name
 }
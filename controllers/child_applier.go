@@ -0,0 +1,297 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Open Component Model contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/fluxcd/pkg/runtime/conditions"
+	"github.com/fluxcd/pkg/ssa"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	mpasv1alpha1 "github.com/open-component-model/mpas-project-controller/api/v1alpha1"
+	"github.com/open-component-model/mpas-project-controller/inventory"
+)
+
+// legacyFieldManagers are the field managers the controller updated its children with before it used
+// server-side apply. Their fields are handed over to the apply field manager of the controller, so that
+// fields that are removed from the desired state are removed from the children as well.
+var legacyFieldManagers = []ssa.FieldManager{
+	{
+		Name:          "manager",
+		OperationType: metav1.ManagedFieldsOperationUpdate,
+	},
+}
+
+// childApplier server-side applies the children of a Project during a single reconciliation. It records
+// the outcome for each child in a change set and keeps track of the children that have drifted or were
+// changed by someone else.
+type childApplier struct {
+	client    client.Client
+	reader    client.Reader
+	manager   *ssa.ResourceManager
+	scheme    *runtime.Scheme
+	changeSet *ssa.ChangeSet
+
+	// reportOnly prevents drifted children from being corrected.
+	reportOnly bool
+	// drifted contains the drifted children in the format <kind>/<namespace>/<name>.
+	drifted []string
+
+	// previous contains the inventory entries of the last reconciliation by their ID.
+	previous map[string]mpasv1alpha1.ResourceRef
+	// applied contains the checksums of the desired state of the applied children by their ID.
+	applied map[string]string
+	// recreated contains the children that were deleted and recreated by someone else.
+	recreated []string
+	// changed contains the children whose spec was changed by someone else in fields that aren't
+	// managed by the controller.
+	changed []string
+}
+
+func (r *ProjectReconciler) newChildApplier(obj *mpasv1alpha1.Project) *childApplier {
+	applier := r.newSharedApplier()
+	applier.reportOnly = obj.Spec.DriftPolicy == mpasv1alpha1.DriftPolicyReport
+	applier.previous = inventory.Entries(obj.Status.Inventory)
+
+	return applier
+}
+
+// newSharedApplier returns an applier for objects that are shared by all Projects. They are not part of
+// the inventory of a Project, so they are always applied and never reported as drifted.
+func (r *ProjectReconciler) newSharedApplier() *childApplier {
+	return &childApplier{
+		client: r.Client,
+		reader: r.apiReader(),
+		manager: ssa.NewResourceManager(r.Client, nil, ssa.Owner{
+			Field: ControllerName,
+			Group: mpasv1alpha1.GroupVersion.Group,
+		}),
+		scheme:    r.Scheme,
+		changeSet: ssa.NewChangeSet(),
+		previous:  map[string]mpasv1alpha1.ResourceRef{},
+		applied:   map[string]string{},
+	}
+}
+
+// apply server-side applies the given objects with the field manager of the controller and records the
+// outcome for each of them in the change set. The objects are updated with their live state afterwards.
+func (a *childApplier) apply(ctx context.Context, objects ...client.Object) error {
+	for _, child := range objects {
+		u, err := a.toUnstructured(child)
+		if err != nil {
+			return err
+		}
+
+		id := object.UnstructuredToObjMetadata(u).String()
+		checksum, err := desiredChecksum(u)
+		if err != nil {
+			return err
+		}
+
+		// A child can only have drifted if its desired state didn't change since it was last applied,
+		// otherwise the controller itself changes it.
+		previous, ok := a.previous[id]
+		unchanged := ok && previous.AppliedChecksum != "" && previous.AppliedChecksum == checksum
+		a.applied[id] = checksum
+
+		entry, err := a.applyOne(ctx, u, unchanged)
+		if err != nil {
+			return err
+		}
+
+		a.changeSet.Add(*entry)
+
+		if err := a.observe(ctx, child, u, entry.Action, unchanged); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// recordAppliedChecksums records the checksums of the desired state of the applied children in the inventory,
+// so that the next reconciliation can tell drift apart from changes of the desired state.
+func (a *childApplier) recordAppliedChecksums(inv *mpasv1alpha1.ResourceInventory) {
+	for i, entry := range inv.Entries {
+		if checksum, ok := a.applied[entry.ID]; ok {
+			inv.Entries[i].AppliedChecksum = checksum
+		}
+	}
+}
+
+func (a *childApplier) applyOne(ctx context.Context, u *unstructured.Unstructured, detectDrift bool) (*ssa.ChangeSetEntry, error) {
+	if detectDrift && a.reportOnly {
+		entry, _, _, err := a.manager.Diff(ctx, u, ssa.DefaultDiffOptions())
+		if err != nil {
+			return nil, err
+		}
+
+		if entry.Action == ssa.ConfiguredAction {
+			a.drifted = append(a.drifted, entry.Subject)
+			entry.Action = ssa.SkippedAction
+
+			return entry, nil
+		}
+	}
+
+	opts := ssa.DefaultApplyOptions()
+	opts.Cleanup = ssa.ApplyCleanupOptions{
+		FieldManagers: legacyFieldManagers,
+	}
+
+	entry, err := a.manager.Apply(ctx, u, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if detectDrift && entry.Action == ssa.ConfiguredAction {
+		a.drifted = append(a.drifted, entry.Subject)
+	}
+
+	return entry, nil
+}
+
+// observe reads the live state of an applied child, so that its UID and spec checksum are recorded in the
+// inventory, and compares them with the previous inventory entry of the child. The child is read from the
+// API server, because the cache may not contain the applied state yet. Changes by someone else are only
+// detected if the desired state of the child didn't change, since the controller changed it otherwise.
+func (a *childApplier) observe(ctx context.Context, child client.Object, u *unstructured.Unstructured, action ssa.Action, unchanged bool) error {
+	if err := a.reader.Get(ctx, client.ObjectKeyFromObject(child), child); err != nil {
+		// A child that is gone right after it was applied has been deleted by someone else in the meantime.
+		return fmt.Errorf("failed to get %s: %w", ssa.FmtUnstructured(u), err)
+	}
+
+	previous, ok := a.previous[object.UnstructuredToObjMetadata(u).String()]
+	if !ok {
+		return nil
+	}
+
+	// A child that was created by the controller replaces one that was deleted.
+	if previous.UID != "" && previous.UID != child.GetUID() && action != ssa.CreatedAction {
+		a.recreated = append(a.recreated, ssa.FmtUnstructured(u))
+
+		return nil
+	}
+
+	if !unchanged || previous.Checksum == "" {
+		return nil
+	}
+
+	checksum, err := inventory.Checksum(child)
+	if err != nil {
+		return err
+	}
+
+	if checksum != previous.Checksum {
+		a.changed = append(a.changed, ssa.FmtUnstructured(u))
+	}
+
+	return nil
+}
+
+// desiredChecksum returns the SHA-256 checksum of the desired state of a child.
+func desiredChecksum(u *unstructured.Unstructured) (string, error) {
+	data, err := json.Marshal(u.Object)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal %s: %w", ssa.FmtUnstructured(u), err)
+	}
+
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(data)), nil
+}
+
+func (a *childApplier) toUnstructured(object client.Object) (*unstructured.Unstructured, error) {
+	gvk, err := apiutil.GVKForObject(object, a.scheme)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group version kind: %w", err)
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert object to unstructured: %w", err)
+	}
+
+	// The status is owned by the controller of the object and the creation timestamp by the API server.
+	u := &unstructured.Unstructured{Object: content}
+	u.SetGroupVersionKind(gvk)
+	unstructured.RemoveNestedField(u.Object, "status")
+	unstructured.RemoveNestedField(u.Object, "metadata", "creationTimestamp")
+
+	return u, nil
+}
+
+// recordChangeSet reports the children of the Project that were created or configured in an event.
+func (r *ProjectReconciler) recordChangeSet(obj *mpasv1alpha1.Project, applier *childApplier) {
+	var changes []string
+	for _, entry := range applier.changeSet.Entries {
+		if entry.Action == ssa.CreatedAction || entry.Action == ssa.ConfiguredAction {
+			changes = append(changes, entry.String())
+		}
+	}
+
+	if len(changes) == 0 {
+		return
+	}
+
+	r.Event(obj, corev1.EventTypeNormal, mpasv1alpha1.ChangesAppliedReason, strings.Join(changes, "\n"))
+}
+
+// recordDrift reports the drifted children of the Project in an event and in the status of the Project.
+func (r *ProjectReconciler) recordDrift(obj *mpasv1alpha1.Project, applier *childApplier) {
+	if len(applier.drifted) == 0 {
+		return
+	}
+
+	obj.Status.LastDrift = &mpasv1alpha1.DriftReport{
+		DetectedAt: metav1.Now(),
+		Resources:  applier.drifted,
+		Corrected:  !applier.reportOnly,
+	}
+
+	action := "corrected"
+	if applier.reportOnly {
+		action = "reported"
+	}
+
+	r.Eventf(obj, corev1.EventTypeWarning, mpasv1alpha1.DriftDetectedReason,
+		"drift %s for %s", action, strings.Join(applier.drifted, ", "))
+}
+
+// recordForeignChanges reports the children of the Project that were recreated or changed by someone else
+// in events and in the ForeignChanges condition of the Project.
+func (r *ProjectReconciler) recordForeignChanges(obj *mpasv1alpha1.Project, applier *childApplier) {
+	if len(applier.recreated) == 0 && len(applier.changed) == 0 {
+		conditions.Delete(obj, mpasv1alpha1.ForeignChangesCondition)
+
+		return
+	}
+
+	var messages []string
+	reason := mpasv1alpha1.UnexpectedChangeReason
+	if len(applier.changed) > 0 {
+		message := fmt.Sprintf("changed unexpectedly: %s", strings.Join(applier.changed, ", "))
+		r.Event(obj, corev1.EventTypeWarning, mpasv1alpha1.UnexpectedChangeReason, message)
+		messages = append(messages, message)
+	}
+
+	if len(applier.recreated) > 0 {
+		message := fmt.Sprintf("recreated by someone else: %s", strings.Join(applier.recreated, ", "))
+		r.Event(obj, corev1.EventTypeWarning, mpasv1alpha1.ForeignRecreationReason, message)
+		messages = append([]string{message}, messages...)
+		reason = mpasv1alpha1.ForeignRecreationReason
+	}
+
+	conditions.MarkTrue(obj, mpasv1alpha1.ForeignChangesCondition, reason, strings.Join(messages, "; "))
+}
@@ -0,0 +1,210 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Open Component Model contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1"
+	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/fluxcd/pkg/runtime/conditions"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	gcv1alpha1 "github.com/open-component-model/git-controller/apis/mpas/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	mpasv1alpha1 "github.com/open-component-model/mpas-project-controller/api/v1alpha1"
+)
+
+// childConditionTypes contains the conditions reflecting the readiness of the children of a Project.
+// The Ready condition of the Project is the summary of these conditions.
+var childConditionTypes = []string{
+	mpasv1alpha1.RepositoryReadyCondition,
+	mpasv1alpha1.SourceReadyCondition,
+	mpasv1alpha1.KustomizationsReadyCondition,
+	mpasv1alpha1.CertificateReadyCondition,
+}
+
+// markChildConditions sets the readiness conditions of the Project from the current status of the given children.
+func (r *ProjectReconciler) markChildConditions(ctx context.Context, obj *mpasv1alpha1.Project, objects []runtime.Object) error {
+	children := make(map[string][]client.Object, len(childConditionTypes))
+	for _, object := range objects {
+		switch child := object.(type) {
+		case *gcv1alpha1.Repository:
+			children[mpasv1alpha1.RepositoryReadyCondition] = append(children[mpasv1alpha1.RepositoryReadyCondition], child)
+		case *sourcev1.GitRepository:
+			children[mpasv1alpha1.SourceReadyCondition] = append(children[mpasv1alpha1.SourceReadyCondition], child)
+		case *kustomizev1.Kustomization:
+			children[mpasv1alpha1.KustomizationsReadyCondition] = append(children[mpasv1alpha1.KustomizationsReadyCondition], child)
+		case *certmanagerv1.Certificate:
+			children[mpasv1alpha1.CertificateReadyCondition] = append(children[mpasv1alpha1.CertificateReadyCondition], child)
+		}
+	}
+
+	for _, t := range childConditionTypes {
+		if err := r.markChildCondition(ctx, obj, t, children[t]); err != nil {
+			return err
+		}
+	}
+
+	// A certificate can't become ready without its issuer, so a missing issuer is the more precise reason.
+	if len(children[mpasv1alpha1.CertificateReadyCondition]) > 0 && !conditions.IsTrue(obj, mpasv1alpha1.CertificateReadyCondition) {
+		if err := r.checkClusterIssuer(ctx); err != nil {
+			if !errors.Is(err, errClusterIssuerNotFound) {
+				return err
+			}
+
+			conditions.MarkFalse(obj, mpasv1alpha1.CertificateReadyCondition, mpasv1alpha1.ClusterIssuerNotFoundReason, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (r *ProjectReconciler) markChildCondition(
+	ctx context.Context,
+	obj *mpasv1alpha1.Project,
+	conditionType string,
+	children []client.Object,
+) error {
+	var failed, progressing []string
+	for _, child := range children {
+		// The applied objects don't carry the status, so it is read from the cluster.
+		if err := r.Get(ctx, client.ObjectKeyFromObject(child), child); err != nil {
+			if apierrors.IsNotFound(err) {
+				progressing = append(progressing, fmt.Sprintf("%s: not found", child.GetName()))
+
+				continue
+			}
+
+			return fmt.Errorf("failed to get %s: %w", child.GetName(), err)
+		}
+
+		result, err := childStatus(child)
+		if err != nil {
+			return fmt.Errorf("failed to compute status of %s: %w", child.GetName(), err)
+		}
+
+		switch result.Status {
+		case status.CurrentStatus:
+		case status.FailedStatus:
+			failed = append(failed, fmt.Sprintf("%s: %s", child.GetName(), result.Message))
+		default:
+			progressing = append(progressing, fmt.Sprintf("%s: %s", child.GetName(), result.Message))
+		}
+	}
+
+	switch {
+	case len(failed) > 0:
+		conditions.MarkFalse(obj, conditionType, meta.FailedReason, strings.Join(failed, "; "))
+	case len(progressing) > 0:
+		conditions.MarkUnknown(obj, conditionType, meta.ProgressingReason, strings.Join(progressing, "; "))
+	default:
+		conditions.MarkTrue(obj, conditionType, meta.SucceededReason, "all resources are ready")
+	}
+
+	return nil
+}
+
+// childStatus computes the kstatus of a child resource. Resources that only report a Ready condition,
+// like cert-manager Certificates, are in progress until that condition turns true. kstatus considers
+// resources without a status as current, so the known children are in progress until their controller
+// reported a Ready condition and, if it reports one, the observed generation.
+func childStatus(child client.Object) (*status.Result, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(child)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert object to unstructured: %w", err)
+	}
+
+	result, err := status.Compute(&unstructured.Unstructured{Object: content})
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Status != status.CurrentStatus {
+		return result, nil
+	}
+
+	if reportsObservedGeneration(child) {
+		if _, found, err := unstructured.NestedInt64(content, "status", "observedGeneration"); err != nil || !found {
+			return &status.Result{
+				Status:  status.InProgressStatus,
+				Message: "waiting to be reconciled",
+			}, nil
+		}
+	}
+
+	withConditions, err := status.GetObjectWithConditions(content)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range withConditions.Status.Conditions {
+		if c.Type != meta.ReadyCondition {
+			continue
+		}
+
+		if c.Status != corev1.ConditionTrue {
+			return &status.Result{
+				Status:  status.InProgressStatus,
+				Message: c.Message,
+			}, nil
+		}
+
+		return result, nil
+	}
+
+	return &status.Result{
+		Status:  status.InProgressStatus,
+		Message: "waiting for the Ready condition",
+	}, nil
+}
+
+// reportsObservedGeneration returns true for the children whose controller records the observed generation
+// in their status.
+func reportsObservedGeneration(child client.Object) bool {
+	switch child.(type) {
+	case *gcv1alpha1.Repository, *sourcev1.GitRepository, *kustomizev1.Kustomization:
+		return true
+	default:
+		return false
+	}
+}
+
+// requestsForProjectChild requeues the Project that created a child resource, so that changed or deleted
+// children are restored right away and the readiness conditions of the Project follow the status of its
+// children. The Project is found by the labels of the child, since owner references can't be set across
+// namespaces.
+func (r *ProjectReconciler) requestsForProjectChild(obj client.Object) []reconcile.Request {
+	labels := obj.GetLabels()
+	name, ok := labels[mpasv1alpha1.ProjectKey]
+	if !ok {
+		return nil
+	}
+
+	namespace, ok := labels[mpasv1alpha1.ProjectNamespaceKey]
+	if !ok {
+		return nil
+	}
+
+	return []reconcile.Request{
+		{
+			NamespacedName: types.NamespacedName{
+				Name:      name,
+				Namespace: namespace,
+			},
+		},
+	}
+}
@@ -0,0 +1,80 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Open Component Model contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	mpasv1alpha1 "github.com/open-component-model/mpas-project-controller/api/v1alpha1"
+)
+
+var (
+	errClusterRoleNotFound   = errors.New("cluster role not found")
+	errClusterIssuerNotFound = errors.New("cluster issuer not found")
+)
+
+// checkClusterIssuer makes sure the ClusterIssuer that the project certificates are requested from exists.
+func (r *ProjectReconciler) checkClusterIssuer(ctx context.Context) error {
+	issuer := &certmanagerv1.ClusterIssuer{}
+	if err := r.Get(ctx, types.NamespacedName{Name: r.IssuerName}, issuer); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("%w: %s", errClusterIssuerNotFound, r.IssuerName)
+		}
+
+		return fmt.Errorf("failed to get cluster issuer %s: %w", r.IssuerName, err)
+	}
+
+	return nil
+}
+
+// requestsForClusterRole requeues all Projects when the ClusterRole that is bound in every project
+// namespace is created or changed.
+func (r *ProjectReconciler) requestsForClusterRole(obj client.Object) []reconcile.Request {
+	if obj.GetName() != r.ClusterRoleName {
+		return nil
+	}
+
+	return r.requestsForAllProjects("clusterrole", obj.GetName())
+}
+
+// requestsForClusterIssuer requeues all Projects when the ClusterIssuer of the project certificates is
+// created or changed.
+func (r *ProjectReconciler) requestsForClusterIssuer(obj client.Object) []reconcile.Request {
+	if obj.GetName() != r.IssuerName {
+		return nil
+	}
+
+	return r.requestsForAllProjects("clusterissuer", obj.GetName())
+}
+
+// requestsForAllProjects returns a request for every Project. The kind and name of the object that
+// triggered the requests are only used for logging.
+func (r *ProjectReconciler) requestsForAllProjects(kind, name string) []reconcile.Request {
+	ctx := context.Background()
+	projects := &mpasv1alpha1.ProjectList{}
+	if err := r.List(ctx, projects); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list projects for "+kind+" change", kind, name)
+
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(projects.Items))
+	for _, project := range projects.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: client.ObjectKeyFromObject(&project),
+		})
+	}
+
+	return requests
+}
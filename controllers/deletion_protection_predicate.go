@@ -0,0 +1,49 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Open Component Model contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/open-component-model/mpas-project-controller/api/v1alpha1"
+)
+
+// DeletionProtectionRemovedPredicate triggers a reconciliation when the deletion protection of a Project
+// is lifted or its deletion is forced, so that a blocked deletion can continue.
+type DeletionProtectionRemovedPredicate struct {
+	predicate.Funcs
+}
+
+// Update will check if the deletion protection annotation was removed from the Project or the force
+// delete annotation was added.
+func (DeletionProtectionRemovedPredicate) Update(e event.UpdateEvent) bool {
+	if e.ObjectOld == nil || e.ObjectNew == nil {
+		return false
+	}
+
+	oldProtected := e.ObjectOld.GetAnnotations()[v1alpha1.DeletionProtectionAnnotationKey] == "true"
+	newProtected := e.ObjectNew.GetAnnotations()[v1alpha1.DeletionProtectionAnnotationKey] == "true"
+
+	oldForced := e.ObjectOld.GetAnnotations()[v1alpha1.ForceDeleteAnnotationKey] == "true"
+	newForced := e.ObjectNew.GetAnnotations()[v1alpha1.ForceDeleteAnnotationKey] == "true"
+
+	return (oldProtected && !newProtected) || (!oldForced && newForced)
+}
+
+// Create is handled by the other predicates of the Project watch.
+func (DeletionProtectionRemovedPredicate) Create(event.CreateEvent) bool {
+	return false
+}
+
+// Delete is handled by the other predicates of the Project watch.
+func (DeletionProtectionRemovedPredicate) Delete(event.DeleteEvent) bool {
+	return false
+}
+
+// Generic is handled by the other predicates of the Project watch.
+func (DeletionProtectionRemovedPredicate) Generic(event.GenericEvent) bool {
+	return false
+}
@@ -0,0 +1,135 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Open Component Model contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	gcv1alpha1 "github.com/open-component-model/git-controller/apis/mpas/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	mpasv1alpha1 "github.com/open-component-model/mpas-project-controller/api/v1alpha1"
+	"github.com/open-component-model/mpas-project-controller/inventory"
+)
+
+var (
+	errExistingResourceConflict   = errors.New("resource already exists and is not owned by the project")
+	errNamespaceOwnershipConflict = errors.New("namespace belongs to another project")
+)
+
+// checkNamespaceOwnership verifies that the project namespace, if it already exists, is not labelled
+// as belonging to a different Project.
+func (r *ProjectReconciler) checkNamespaceOwnership(ctx context.Context, obj *mpasv1alpha1.Project) error {
+	ns := &corev1.Namespace{}
+	if err := r.Get(ctx, client.ObjectKey{Name: obj.GetNameWithPrefix(r.Prefix)}, ns); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to get namespace: %w", err)
+	}
+
+	name, ok := ns.Labels[mpasv1alpha1.ProjectKey]
+	if ok && name != obj.Name {
+		return fmt.Errorf("%w: namespace %s is labelled with %s=%s", errNamespaceOwnershipConflict, ns.Name, mpasv1alpha1.ProjectKey, name)
+	}
+
+	namespace, ok := ns.Labels[mpasv1alpha1.ProjectNamespaceKey]
+	if ok && namespace != obj.Namespace {
+		return fmt.Errorf("%w: namespace %s is labelled with %s=%s",
+			errNamespaceOwnershipConflict, ns.Name, mpasv1alpha1.ProjectNamespaceKey, namespace)
+	}
+
+	return nil
+}
+
+// checkExistingResources verifies that the project namespace, Repository and GitRepository may be adopted
+// according to the ExistingResourcePolicy of the Project, in case they already exist and are not owned by it.
+func (r *ProjectReconciler) checkExistingResources(ctx context.Context, obj *mpasv1alpha1.Project) error {
+	name := obj.GetNameWithPrefix(r.Prefix)
+	resources := []client.Object{
+		&corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+		},
+		&gcv1alpha1.Repository{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: r.DefaultNamespace,
+			},
+		},
+		&sourcev1.GitRepository{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: r.DefaultNamespace,
+			},
+		},
+	}
+
+	for _, resource := range resources {
+		gvk, err := apiutil.GVKForObject(resource, r.Scheme)
+		if err != nil {
+			return fmt.Errorf("failed to get gvk for object: %w", err)
+		}
+
+		if err := r.Get(ctx, client.ObjectKeyFromObject(resource), resource); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+
+			return fmt.Errorf("failed to get %s %s: %w", gvk.Kind, resource.GetName(), err)
+		}
+
+		owned, err := r.isOwnedByProject(obj, resource, object.ObjMetadata{
+			Namespace: resource.GetNamespace(),
+			Name:      resource.GetName(),
+			GroupKind: gvk.GroupKind(),
+		})
+		if err != nil {
+			return err
+		}
+
+		if owned {
+			continue
+		}
+
+		switch obj.Spec.ExistingResourcePolicy {
+		case mpasv1alpha1.ExistingResourcePolicyFail:
+			return fmt.Errorf("%w: %s %s", errExistingResourceConflict, gvk.Kind, resource.GetName())
+		case mpasv1alpha1.ExistingResourcePolicyAdoptIfLabelled:
+			if resource.GetLabels()[mpasv1alpha1.ProjectKey] != obj.Name {
+				return fmt.Errorf("%w: %s %s is not labelled with %s=%s",
+					errExistingResourceConflict, gvk.Kind, resource.GetName(), mpasv1alpha1.ProjectKey, obj.Name)
+			}
+		case mpasv1alpha1.ExistingResourcePolicyAdopt:
+		}
+	}
+
+	return nil
+}
+
+// isOwnedByProject returns true if the resource is part of the inventory of the Project or has been
+// labelled by the controller as belonging to it.
+func (r *ProjectReconciler) isOwnedByProject(obj *mpasv1alpha1.Project, resource client.Object, id object.ObjMetadata) (bool, error) {
+	labels := resource.GetLabels()
+	if labels[mpasv1alpha1.ProjectKey] == obj.Name && labels[mpasv1alpha1.ProjectNamespaceKey] == obj.Namespace {
+		return true, nil
+	}
+
+	if obj.Status.Inventory == nil {
+		return false, nil
+	}
+
+	return inventory.Contains(obj.Status.Inventory, id), nil
+}
@@ -0,0 +1,37 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Open Component Model contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	mpasv1alpha1 "github.com/open-component-model/mpas-project-controller/api/v1alpha1"
+	"github.com/open-component-model/mpas-project-controller/inventory"
+)
+
+// migrateInventoryVersions moves the inventory entries of the Project to the API versions served by the
+// cluster before they are used to prune or delete objects, and reports the migrated kinds in an event.
+// The migrated versions are stored with the next status patch.
+func (r *ProjectReconciler) migrateInventoryVersions(ctx context.Context, obj *mpasv1alpha1.Project) error {
+	migrations, err := inventory.MigrateVersions(obj.Status.Inventory, r.Client.RESTMapper())
+	if err != nil {
+		return fmt.Errorf("failed to migrate inventory versions: %w", err)
+	}
+
+	if len(migrations) == 0 {
+		return nil
+	}
+
+	log.FromContext(ctx).Info("migrated inventory versions", "migrations", migrations)
+	r.Eventf(obj, corev1.EventTypeNormal, mpasv1alpha1.InventoryMigratedReason,
+		"migrated inventory entries to the served API versions: %s", strings.Join(migrations, ", "))
+
+	return nil
+}
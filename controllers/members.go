@@ -0,0 +1,157 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Open Component Model contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	mpasv1alpha1 "github.com/open-component-model/mpas-project-controller/api/v1alpha1"
+)
+
+// projectRoles contains the roles that can be granted to project members, in the order they are reconciled.
+var projectRoles = []mpasv1alpha1.ProjectRole{
+	mpasv1alpha1.ProjectRoleAdmin,
+	mpasv1alpha1.ProjectRoleEditor,
+	mpasv1alpha1.ProjectRoleViewer,
+}
+
+// memberClusterRoleName returns the name of the ClusterRole that is bound for members with the given role.
+func (r *ProjectReconciler) memberClusterRoleName(role mpasv1alpha1.ProjectRole) string {
+	return fmt.Sprintf("%s-project-%s", r.Prefix, role)
+}
+
+// memberPolicyRules returns the rules of the ClusterRole for members with the given role.
+func memberPolicyRules(role mpasv1alpha1.ProjectRole, projectRules []rbacv1.PolicyRule) []rbacv1.PolicyRule {
+	rules := make([]rbacv1.PolicyRule, len(projectRules))
+	copy(rules, projectRules)
+
+	switch role {
+	case mpasv1alpha1.ProjectRoleAdmin:
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: []string{"rbac.authorization.k8s.io"},
+			Resources: []string{"roles", "rolebindings"},
+			Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+		})
+	case mpasv1alpha1.ProjectRoleViewer:
+		viewRules := make([]rbacv1.PolicyRule, 0, len(rules))
+		for _, rule := range rules {
+			// Viewers must not be able to read the credentials of the project.
+			if len(rule.APIGroups) == 1 && rule.APIGroups[0] == "" {
+				continue
+			}
+
+			rule.Verbs = []string{"get", "list", "watch"}
+			viewRules = append(viewRules, rule)
+		}
+
+		rules = viewRules
+	case mpasv1alpha1.ProjectRoleEditor:
+	}
+
+	return rules
+}
+
+// reconcileMemberClusterRoles makes sure the ClusterRoles that are bound for project members exist and grant
+// access to the project resources. They are shared by all projects and are therefore not part of the inventory
+// of a project: they are created by the first Project with members, updated by every Project with members and
+// kept when Projects are deleted. Removing them once no Project has members is left to the operator.
+// Since a change of their desired state is observed by every Project, they are applied with the shared
+// applier instead of the applier of the Project, which would report the change as drift.
+func (r *ProjectReconciler) reconcileMemberClusterRoles(ctx context.Context, projectRules []rbacv1.PolicyRule) error {
+	applier := r.newSharedApplier()
+	for _, role := range projectRoles {
+		cr := &rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: r.memberClusterRoleName(role),
+			},
+		}
+
+		cr.Rules = memberPolicyRules(role, projectRules)
+
+		cr.Labels = make(map[string]string)
+		r.applyMandatoryLabels("clusterrole", "rbac", "clusterrole", cr.Labels)
+
+		if err := applier.apply(ctx, cr); err != nil {
+			return fmt.Errorf("failed to create or update member cluster role %s: %w", cr.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// reconcileMemberRoleBindings creates a RoleBinding in the project namespace for each role that is granted
+// to at least one member.
+func (r *ProjectReconciler) reconcileMemberRoleBindings(
+	ctx context.Context,
+	applier *childApplier,
+	obj *mpasv1alpha1.Project,
+	projectRules []rbacv1.PolicyRule,
+) ([]*rbacv1.RoleBinding, error) {
+	if len(obj.Spec.Members) == 0 {
+		return nil, nil
+	}
+
+	if err := r.reconcileMemberClusterRoles(ctx, projectRules); err != nil {
+		return nil, err
+	}
+
+	name := obj.GetNameWithPrefix(r.Prefix)
+	subjects := make(map[mpasv1alpha1.ProjectRole][]rbacv1.Subject)
+
+	for _, member := range obj.Spec.Members {
+		subject := rbacv1.Subject{
+			Kind: member.Kind,
+			Name: member.Name,
+		}
+
+		if member.Kind == rbacv1.ServiceAccountKind {
+			subject.Namespace = member.Namespace
+			if subject.Namespace == "" {
+				subject.Namespace = name
+			}
+		} else {
+			subject.APIGroup = rbacv1.GroupName
+		}
+
+		subjects[member.Role] = append(subjects[member.Role], subject)
+	}
+
+	var roleBindings []*rbacv1.RoleBinding
+	for _, role := range projectRoles {
+		if len(subjects[role]) == 0 {
+			continue
+		}
+
+		roleBinding := &rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-members-%s", name, role),
+				Namespace: name,
+			},
+		}
+
+		roleBinding.Subjects = subjects[role]
+		roleBinding.RoleRef = rbacv1.RoleRef{
+			Kind:     "ClusterRole",
+			Name:     r.memberClusterRoleName(role),
+			APIGroup: rbacv1.GroupName,
+		}
+
+		roleBinding.Labels = make(map[string]string)
+		r.applyMandatoryLabels("rolebinding", "rbac", "rolebinding", roleBinding.Labels)
+		r.applyProjectLabels(obj, roleBinding.Labels)
+
+		if err := applier.apply(ctx, roleBinding); err != nil {
+			return nil, fmt.Errorf("failed to create or update member role binding: %w", err)
+		}
+
+		roleBindings = append(roleBindings, roleBinding)
+	}
+
+	return roleBindings, nil
+}
@@ -0,0 +1,195 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Open Component Model contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	mpasv1alpha1 "github.com/open-component-model/mpas-project-controller/api/v1alpha1"
+)
+
+const (
+	// namespaceNameLabel is set by Kubernetes on every namespace and contains its name.
+	namespaceNameLabel = "kubernetes.io/metadata.name"
+	dnsPort            = 53
+)
+
+func (r *ProjectReconciler) reconcileNetworkPolicies(ctx context.Context, applier *childApplier, obj *mpasv1alpha1.Project) ([]*networkingv1.NetworkPolicy, error) {
+	specs, err := r.networkPolicySpecs(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	name := obj.GetNameWithPrefix(r.Prefix)
+	policies := make([]*networkingv1.NetworkPolicy, 0, len(specs))
+
+	suffixes := make([]string, 0, len(specs))
+	for suffix := range specs {
+		suffixes = append(suffixes, suffix)
+	}
+	sort.Strings(suffixes)
+
+	for _, suffix := range suffixes {
+		spec := specs[suffix]
+		policy := &networkingv1.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name + "-" + suffix,
+				Namespace: name,
+			},
+		}
+
+		policy.Spec = spec
+
+		policy.Labels = make(map[string]string)
+
+		r.applyMandatoryLabels("networkpolicy", "network", "networkpolicy", policy.Labels)
+		r.applyProjectLabels(obj, policy.Labels)
+
+		if err := applier.apply(ctx, policy); err != nil {
+			return nil, fmt.Errorf("failed to create or update network policy: %w", err)
+		}
+
+		policies = append(policies, policy)
+	}
+
+	return policies, nil
+}
+
+// networkPolicySpecs returns the NetworkPolicies for the project namespace keyed by their name suffix.
+func (r *ProjectReconciler) networkPolicySpecs(obj *mpasv1alpha1.Project) (map[string]networkingv1.NetworkPolicySpec, error) {
+	mode := obj.GetNetworkPolicyMode()
+	if mode == mpasv1alpha1.NetworkPolicyModeDisabled {
+		return nil, nil
+	}
+
+	udp, tcp := corev1.ProtocolUDP, corev1.ProtocolTCP
+	port := intstr.FromInt(dnsPort)
+	allPods := metav1.LabelSelector{}
+
+	specs := map[string]networkingv1.NetworkPolicySpec{
+		"default-deny": {
+			PodSelector: allPods,
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+		},
+		"allow-dns": {
+			PodSelector: allPods,
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+			Egress: []networkingv1.NetworkPolicyEgressRule{
+				{
+					To: []networkingv1.NetworkPolicyPeer{
+						{
+							NamespaceSelector: namespaceSelector(metav1.NamespaceSystem),
+							PodSelector: &metav1.LabelSelector{
+								MatchLabels: map[string]string{"k8s-app": "kube-dns"},
+							},
+						},
+					},
+					Ports: []networkingv1.NetworkPolicyPort{
+						{Protocol: &udp, Port: &port},
+						{Protocol: &tcp, Port: &port},
+					},
+				},
+			},
+		},
+		"allow-same-namespace": {
+			PodSelector: allPods,
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{
+				{From: []networkingv1.NetworkPolicyPeer{{PodSelector: &allPods}}},
+			},
+			Egress: []networkingv1.NetworkPolicyEgressRule{
+				{To: []networkingv1.NetworkPolicyPeer{{PodSelector: &allPods}}},
+			},
+		},
+	}
+
+	if r.RegistryAddr != "" {
+		registryNamespace, err := registryNamespace(r.RegistryAddr)
+		if err != nil {
+			return nil, err
+		}
+
+		specs["allow-registry"] = networkingv1.NetworkPolicySpec{
+			PodSelector: allPods,
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+			Egress: []networkingv1.NetworkPolicyEgressRule{
+				{To: []networkingv1.NetworkPolicyPeer{{NamespaceSelector: namespaceSelector(registryNamespace)}}},
+			},
+		}
+	}
+
+	// The controllers in the MPAS system namespace must always be able to reach the project namespace.
+	specs["allow-mpas-system"] = networkingv1.NetworkPolicySpec{
+		PodSelector: allPods,
+		PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+		Ingress: []networkingv1.NetworkPolicyIngressRule{
+			{From: []networkingv1.NetworkPolicyPeer{{NamespaceSelector: namespaceSelector(r.DefaultNamespace)}}},
+		},
+		Egress: []networkingv1.NetworkPolicyEgressRule{
+			{To: []networkingv1.NetworkPolicyPeer{{NamespaceSelector: namespaceSelector(r.DefaultNamespace)}}},
+		},
+	}
+
+	if mode == mpasv1alpha1.NetworkPolicyModeCustom {
+		custom := networkingv1.NetworkPolicySpec{
+			PodSelector: allPods,
+		}
+
+		if len(obj.Spec.NetworkPolicy.Ingress) > 0 {
+			custom.PolicyTypes = append(custom.PolicyTypes, networkingv1.PolicyTypeIngress)
+			custom.Ingress = []networkingv1.NetworkPolicyIngressRule{{From: obj.Spec.NetworkPolicy.Ingress}}
+		}
+
+		if len(obj.Spec.NetworkPolicy.Egress) > 0 {
+			custom.PolicyTypes = append(custom.PolicyTypes, networkingv1.PolicyTypeEgress)
+			custom.Egress = []networkingv1.NetworkPolicyEgressRule{{To: obj.Spec.NetworkPolicy.Egress}}
+		}
+
+		if len(custom.PolicyTypes) > 0 {
+			specs["allow-custom"] = custom
+		}
+	}
+
+	return specs, nil
+}
+
+// registryNamespace returns the namespace of the internal registry from its in-cluster service address,
+// e.g. registry.ocm-system.svc.cluster.local:5000. Addresses that aren't the DNS name of a service are
+// rejected, since the namespace can't be derived from them.
+func registryNamespace(addr string) (string, error) {
+	host := addr
+	if h, _, err := net.SplitHostPort(addr); err == nil {
+		host = h
+	}
+
+	// A service is addressed as <service>.<namespace>.svc, optionally followed by the cluster domain, which
+	// isn't necessarily cluster.local.
+	parts := strings.Split(host, ".")
+	if len(parts) < 3 || parts[2] != "svc" {
+		return "", fmt.Errorf("registry address %q is not an in-cluster service address of the form "+
+			"<service>.<namespace>.svc[.<cluster domain>]", addr)
+	}
+
+	if parts[0] == "" || parts[1] == "" {
+		return "", fmt.Errorf("failed to determine namespace of registry address %q", addr)
+	}
+
+	return parts[1], nil
+}
+
+func namespaceSelector(namespace string) *metav1.LabelSelector {
+	return &metav1.LabelSelector{
+		MatchLabels: map[string]string{namespaceNameLabel: namespace},
+	}
+}
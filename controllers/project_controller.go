@@ -9,6 +9,7 @@ import (
 	_ "embed" // embedding
 	"errors"
 	"fmt"
+	"strings"
 
 	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	v1 "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
@@ -16,22 +17,34 @@ import (
 	"github.com/fluxcd/pkg/apis/meta"
 	"github.com/fluxcd/pkg/runtime/conditions"
 	"github.com/fluxcd/pkg/runtime/patch"
+	"github.com/fluxcd/pkg/runtime/predicates"
 	rreconcile "github.com/fluxcd/pkg/runtime/reconcile"
+	"github.com/fluxcd/pkg/ssa"
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
 	gcv1alpha1 "github.com/open-component-model/git-controller/apis/mpas/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/discovery"
+	kuberecorder "k8s.io/client-go/tools/record"
+	"sigs.k8s.io/cli-utils/pkg/object"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	mpasv1alpha1 "github.com/open-component-model/mpas-project-controller/api/v1alpha1"
 	"github.com/open-component-model/mpas-project-controller/inventory"
@@ -54,6 +67,8 @@ const (
 // ProjectReconciler reconciles a Project object.
 type ProjectReconciler struct {
 	client.Client
+	kuberecorder.EventRecorder
+
 	Scheme                *runtime.Scheme
 	ClusterRoleName       string
 	Prefix                string
@@ -61,11 +76,41 @@ type ProjectReconciler struct {
 	DefaultNamespace      string
 	IssuerName            string
 	RegistryAddr          string
+	// AllowedRBACAPIGroups contains the API groups that additional project RBAC rules may refer to.
+	AllowedRBACAPIGroups []string
+	// AllowedRBACVerbs contains the verbs that additional project RBAC rules may grant.
+	AllowedRBACVerbs []string
+	// AllowedRBACResources contains the resources that additional project RBAC rules may refer to.
+	AllowedRBACResources []string
+	// DiscoveryClient is used to build the project Role from the resources served by the cluster.
+	// If nil, the project Role contains a static set of rules.
+	DiscoveryClient discovery.DiscoveryInterface
+	// ProjectRoleResources selects the resources the project Role grants access to. Entries are in the
+	// format <group>/<resource>, <group>/* selects all namespaced resources of an API group.
+	ProjectRoleResources []string
+	// DeletionBlockingKinds contains the kinds in the format <group>/<kind> that block the deletion of
+	// a Project that opted in while resources of these kinds exist in the project namespace.
+	DeletionBlockingKinds []string
+	// InventoryThreshold is the size in bytes of the encoded inventory above which it is stored in a
+	// ConfigMap next to the Project instead of its status. Zero keeps all inventories in the status.
+	InventoryThreshold int
+	// APIReader reads directly from the API server, bypassing the cache of the Client. If nil, the Client
+	// is used.
+	APIReader client.Reader
 }
 
 //+kubebuilder:rbac:groups="",resources=namespaces;serviceaccounts;secrets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=resourcequotas;limitranges,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles;clusterrolebindings,verbs=get;list;watch
+// The shared member ClusterRoles are server-side applied. Creating them requires create, which can't be
+// restricted to resource names, while updates are restricted to their names. The names follow the --prefix
+// flag and must be updated here if it is changed.
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,verbs=create
+//nolint:lll // rbac comment
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,verbs=update;patch,resourceNames=mpas-project-admin;mpas-project-editor;mpas-project-viewer
 //nolint:lll // rbac comment
 //+kubebuilder:rbac:groups=mpas.ocm.software,resources=projects;targets;repositories;productdeployments;productdeploymentgenerators;productdeploymentpipelines,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=mpas.ocm.software,resources=subscriptions,verbs=get;list;watch
@@ -75,20 +120,80 @@ type ProjectReconciler struct {
 //+kubebuilder:rbac:groups=source.toolkit.fluxcd.io;kustomize.toolkit.fluxcd.io,resources=gitrepositories;ocirepositories;kustomizations,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=mpas.ocm.software,resources=projects/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=mpas.ocm.software,resources=projects/finalizers,verbs=update
-//+kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=create;update;get;list;delete;watch
+//+kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=create;update;patch;get;list;delete;watch
+//+kubebuilder:rbac:groups=cert-manager.io,resources=clusterissuers,verbs=get;list;watch
 //+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;watch
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ProjectReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	crdMetadata := &metav1.PartialObjectMetadata{}
+	crdMetadata.SetGroupVersionKind(crdGroupVersionKind)
+
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&mpasv1alpha1.Project{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
-		Owns(&corev1.Namespace{}).
-		Owns(&corev1.ServiceAccount{}).
-		Owns(&rbacv1.Role{}).
-		Owns(&rbacv1.RoleBinding{}).
-		Owns(&gcv1alpha1.Repository{}).
-		Owns(&sourcev1.GitRepository{}).
-		Owns(&kustomizev1.Kustomization{}).
+		For(&mpasv1alpha1.Project{}, builder.WithPredicates(
+			predicate.Or(
+				predicate.GenerationChangedPredicate{},
+				predicates.ReconcileRequestedPredicate{},
+				DeletionProtectionRemovedPredicate{},
+			),
+		)).
+		Watches(
+			&source.Kind{Type: &corev1.Namespace{}},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForProjectChild),
+		).
+		Watches(
+			&source.Kind{Type: &corev1.ServiceAccount{}},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForProjectChild),
+		).
+		Watches(
+			&source.Kind{Type: &corev1.ResourceQuota{}},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForProjectChild),
+		).
+		Watches(
+			&source.Kind{Type: &corev1.LimitRange{}},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForProjectChild),
+		).
+		Watches(
+			&source.Kind{Type: &networkingv1.NetworkPolicy{}},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForProjectChild),
+		).
+		Watches(
+			&source.Kind{Type: &rbacv1.Role{}},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForProjectChild),
+		).
+		Watches(
+			&source.Kind{Type: &rbacv1.RoleBinding{}},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForProjectChild),
+		).
+		Watches(
+			&source.Kind{Type: &gcv1alpha1.Repository{}},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForProjectChild),
+		).
+		Watches(
+			&source.Kind{Type: &sourcev1.GitRepository{}},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForProjectChild),
+		).
+		Watches(
+			&source.Kind{Type: &kustomizev1.Kustomization{}},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForProjectChild),
+		).
+		Watches(
+			&source.Kind{Type: &certmanagerv1.Certificate{}},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForProjectChild),
+		).
+		Watches(
+			&source.Kind{Type: &rbacv1.ClusterRole{}},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForClusterRole),
+		).
+		Watches(
+			&source.Kind{Type: &certmanagerv1.ClusterIssuer{}},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForClusterIssuer),
+		).
+		Watches(
+			&source.Kind{Type: crdMetadata},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForCRDChange),
+		).
 		Complete(r)
 }
 
@@ -109,12 +214,34 @@ func (r *ProjectReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_
 	// Initialize the patch helper with the current version of the object.
 	patchHelper := patch.NewSerialPatcher(obj, r.Client)
 
+	inv, err := r.inventoryStorage().Load(ctx, obj)
+	if err != nil {
+		if !errors.Is(err, inventory.ErrInvalidConfigMap) {
+			return ctrl.Result{}, fmt.Errorf("failed to load inventory: %w", err)
+		}
+
+		// The inventory is rebuilt from the applied children. Objects that were only recorded in the lost
+		// inventory are no longer pruned.
+		logger.Error(err, "failed to load inventory, rebuilding it")
+		inv = nil
+	}
+
+	obj.Status.Inventory = inv
+
 	defer func() {
 		if err := r.finalizeStatus(ctx, obj, patchHelper); err != nil {
 			retErr = errors.Join(retErr, err)
 		}
 	}()
 
+	// Stale objects can't be found with API versions that are no longer served, so neither pruning nor
+	// deleting the Project can proceed.
+	if err := r.migrateInventoryVersions(ctx, obj); err != nil {
+		r.markStalled(mpasv1alpha1.InventoryMigrationFailedReason, obj, err)
+
+		return ctrl.Result{}, err
+	}
+
 	if !controllerutil.ContainsFinalizer(obj, mpasv1alpha1.ProjectFinalizer) {
 		controllerutil.AddFinalizer(obj, mpasv1alpha1.ProjectFinalizer)
 
@@ -124,7 +251,13 @@ func (r *ProjectReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_
 	if obj.DeletionTimestamp != nil {
 		logger.Info("project is being deleted...")
 
-		return ctrl.Result{}, r.finalize(ctx, obj)
+		return r.finalize(ctx, obj)
+	}
+
+	if obj.Spec.Suspend {
+		logger.Info("reconciliation is suspended for this object")
+
+		return ctrl.Result{}, r.suspendFluxResources(ctx, obj)
 	}
 
 	return r.reconcile(ctx, obj, patchHelper)
@@ -133,12 +266,6 @@ func (r *ProjectReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_
 func (r *ProjectReconciler) reconcile(ctx context.Context, obj *mpasv1alpha1.Project, sp *patch.SerialPatcher) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 
-	shouldRequeue := true
-	if conditions.Has(obj, meta.ReconcilingCondition) &&
-		conditions.GetReason(obj, meta.ReconcilingCondition) == mpasv1alpha1.WaitingOnResourcesReason {
-		shouldRequeue = false
-	}
-
 	rreconcile.ProgressiveStatus(false, obj, meta.ProgressingReason, "reconciliation in progress")
 	if err := r.patch(ctx, obj, sp); err != nil {
 		if apierrors.IsNotFound(err) {
@@ -167,26 +294,17 @@ func (r *ProjectReconciler) reconcile(ctx context.Context, obj *mpasv1alpha1.Pro
 		obj.Status.Inventory.DeepCopyInto(oldInventory)
 	}
 
-	objects, err := r.reconcileInventory(ctx, obj)
+	applier := r.newChildApplier(obj)
+
+	objects, err := r.reconcileInventory(ctx, applier, obj)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 
-	// Requeue the project only if it was previously not requeued in order to wait for resources to be created.
-	// If we don't do this, then the resources created above will not be correctly populated when adding to the inventory.
-	if shouldRequeue {
-		logger.Info("waiting for resources to be ready")
-		conditions.MarkUnknown(obj, meta.ReadyCondition, meta.ProgressingReason, "reconciliation in progress")
-		conditions.MarkReconciling(obj, mpasv1alpha1.WaitingOnResourcesReason, "waiting for resources to be ready")
-		if err := r.patch(ctx, obj, sp); err != nil {
-			if apierrors.IsNotFound(err) {
-				return ctrl.Result{}, nil
-			}
-
-			return ctrl.Result{}, fmt.Errorf("failed to patch object: %w", err)
-		}
+	if err := r.setGroupVersionKinds(objects); err != nil {
+		conditions.MarkFalse(obj, meta.ReadyCondition, mpasv1alpha1.ReconciliationFailedReason, err.Error())
 
-		return ctrl.Result{Requeue: true}, nil
+		return ctrl.Result{}, fmt.Errorf("error setting group version kinds: %w", err)
 	}
 
 	newInventory := inventory.New()
@@ -196,12 +314,17 @@ func (r *ProjectReconciler) reconcile(ctx context.Context, obj *mpasv1alpha1.Pro
 		return ctrl.Result{}, fmt.Errorf("error adding resources to inventory: %w", err)
 	}
 
+	applier.recordAppliedChecksums(newInventory)
+
+	// Keep the reference to the inventory ConfigMap, so that it is only rewritten if the inventory changed
+	// and cleaned up once it is replaced.
+	newInventory.ConfigMapRef = oldInventory.ConfigMapRef
 	obj.Status.Inventory = newInventory
 	obj.Status.ObservedGeneration = obj.Generation
 
 	logger.Info("getting stale objects")
 	// If the inventory has changed, then we need to prune.
-	staleObjects, err := inventory.Diff(oldInventory, newInventory)
+	staleObjects, err := inventory.Diff(oldInventory, newInventory, r.Client.RESTMapper())
 	if err != nil {
 		conditions.MarkFalse(obj, meta.ReadyCondition, mpasv1alpha1.ReconciliationFailedReason, err.Error())
 
@@ -216,6 +339,24 @@ func (r *ProjectReconciler) reconcile(ctx context.Context, obj *mpasv1alpha1.Pro
 		return ctrl.Result{}, fmt.Errorf("error pruning stale objects: %w", err)
 	}
 
+	if err := r.markChildConditions(ctx, obj, objects); err != nil {
+		conditions.MarkFalse(obj, meta.ReadyCondition, mpasv1alpha1.ReconciliationFailedReason, err.Error())
+
+		return ctrl.Result{}, fmt.Errorf("error computing status of child resources: %w", err)
+	}
+
+	for _, t := range childConditionTypes {
+		if !conditions.IsTrue(obj, t) {
+			logger.Info("waiting for child resources to be ready")
+			conditions.SetSummary(obj, meta.ReadyCondition, conditions.WithConditions(childConditionTypes...))
+			if conditions.IsUnknown(obj, meta.ReadyCondition) {
+				conditions.MarkReconciling(obj, meta.ProgressingReason, "waiting for child resources to be ready")
+			}
+
+			return ctrl.Result{RequeueAfter: obj.GetRequeueAfter()}, nil
+		}
+	}
+
 	// Resource is ready
 	logger.Info("resource is ready")
 	conditions.MarkTrue(obj, meta.ReadyCondition, meta.SucceededReason, "Reconciliation success")
@@ -223,12 +364,27 @@ func (r *ProjectReconciler) reconcile(ctx context.Context, obj *mpasv1alpha1.Pro
 	return ctrl.Result{RequeueAfter: obj.GetRequeueAfter()}, nil
 }
 
+// setGroupVersionKinds sets the GroupVersionKind of the applied objects from the scheme. Typed objects
+// returned by the client don't carry their TypeMeta, which is needed to record them in the inventory.
+func (r *ProjectReconciler) setGroupVersionKinds(objects []runtime.Object) error {
+	for _, object := range objects {
+		gvk, err := apiutil.GVKForObject(object, r.Scheme)
+		if err != nil {
+			return fmt.Errorf("failed to get group version kind: %w", err)
+		}
+
+		object.GetObjectKind().SetGroupVersionKind(gvk)
+	}
+
+	return nil
+}
+
 func (r *ProjectReconciler) markStalled(reason string, obj *mpasv1alpha1.Project, err error) {
 	conditions.MarkStalled(obj, reason, err.Error())
 	conditions.MarkFalse(obj, meta.ReadyCondition, reason, err.Error())
 }
 
-func (r *ProjectReconciler) reconcileNamespace(ctx context.Context, obj *mpasv1alpha1.Project) (*corev1.Namespace, error) {
+func (r *ProjectReconciler) reconcileNamespace(ctx context.Context, applier *childApplier, obj *mpasv1alpha1.Project) (*corev1.Namespace, error) {
 	name := obj.GetNameWithPrefix(r.Prefix)
 	ns := &corev1.Namespace{
 		ObjectMeta: metav1.ObjectMeta{
@@ -236,27 +392,19 @@ func (r *ProjectReconciler) reconcileNamespace(ctx context.Context, obj *mpasv1a
 		},
 	}
 
-	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, ns, func() error {
-		if ns.Labels == nil {
-			ns.Labels = make(map[string]string)
-		}
-
-		if _, ok := ns.Labels[mpasv1alpha1.ProjectKey]; !ok {
-			ns.Labels[mpasv1alpha1.ProjectKey] = obj.Name
-		}
+	ns.Labels = make(map[string]string)
 
-		r.applyMandatoryLabels("namespace", "namespace", "namespace", ns.Labels)
+	r.applyMandatoryLabels("namespace", "namespace", "namespace", ns.Labels)
+	r.applyProjectLabels(obj, ns.Labels)
 
-		return nil
-	})
-	if err != nil {
+	if err := applier.apply(ctx, ns); err != nil {
 		return nil, fmt.Errorf("failed to create namespace: %w", err)
 	}
 
 	return ns, nil
 }
 
-func (r *ProjectReconciler) reconcileServiceAccount(ctx context.Context, obj *mpasv1alpha1.Project) (*corev1.ServiceAccount, error) {
+func (r *ProjectReconciler) reconcileServiceAccount(ctx context.Context, applier *childApplier, obj *mpasv1alpha1.Project) (*corev1.ServiceAccount, error) {
 	name := obj.GetNameWithPrefix(r.Prefix)
 	sa := &corev1.ServiceAccount{
 		ObjectMeta: metav1.ObjectMeta{
@@ -265,80 +413,139 @@ func (r *ProjectReconciler) reconcileServiceAccount(ctx context.Context, obj *mp
 		},
 	}
 
-	// Get the service account, if it doesn't exist, create it,
-
-	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, sa, func() error {
-		if sa.Labels == nil {
-			sa.Labels = make(map[string]string)
-		}
+	sa.Labels = make(map[string]string)
 
-		r.applyMandatoryLabels("serviceaccount", "rbac", "serviceaccount", sa.Labels)
+	r.applyMandatoryLabels("serviceaccount", "rbac", "serviceaccount", sa.Labels)
+	r.applyProjectLabels(obj, sa.Labels)
 
-		return nil
-	})
-	if err != nil {
+	if err := applier.apply(ctx, sa); err != nil {
 		return nil, fmt.Errorf("failed to create or update service account: %w", err)
 	}
 
 	return sa, nil
 }
 
-func (r *ProjectReconciler) reconcileRole(ctx context.Context, obj *mpasv1alpha1.Project) (*rbacv1.Role, error) {
+func (r *ProjectReconciler) reconcileResourceQuota(ctx context.Context, applier *childApplier, obj *mpasv1alpha1.Project) (*corev1.ResourceQuota, error) {
+	if obj.Spec.Quota == nil {
+		return nil, nil
+	}
+
 	name := obj.GetNameWithPrefix(r.Prefix)
-	role := &rbacv1.Role{
+	quota := &corev1.ResourceQuota{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: name,
 		},
 	}
 
-	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, role, func() error {
-		role.Rules = []rbacv1.PolicyRule{
-			{
-				APIGroups: []string{""},
-				Resources: []string{"secrets"},
-				Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
-			},
-			{
-				APIGroups: []string{"mpas.ocm.software"},
-				Resources: []string{
-					"repositories",
-					"targets",
-					"productdeployments",
-					"productdeploymentgenerators",
-					"productdeploymentpipelines",
-				},
-				Verbs: []string{"get", "list", "watch", "create", "update", "patch", "delete"},
-			},
-			{
-				APIGroups: []string{"delivery.ocm.software"},
-				Resources: []string{"componentsubscriptions", "componentversions", "localizations", "configurations"},
-				Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
-			},
-			{
-				APIGroups: []string{"source.toolkit.fluxcd.io", "kustomize.toolkit.fluxcd.io"},
-				Resources: []string{"ocirepositories", "kustomizations"},
-				Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
-			},
-		}
+	quota.Spec = *obj.Spec.Quota.DeepCopy()
+
+	quota.Labels = make(map[string]string)
+
+	r.applyMandatoryLabels("resourcequota", "quota", "resourcequota", quota.Labels)
+	r.applyProjectLabels(obj, quota.Labels)
+
+	if err := applier.apply(ctx, quota); err != nil {
+		return nil, fmt.Errorf("failed to create or update resource quota: %w", err)
+	}
+
+	return quota, nil
+}
+
+func (r *ProjectReconciler) reconcileLimitRange(ctx context.Context, applier *childApplier, obj *mpasv1alpha1.Project) (*corev1.LimitRange, error) {
+	if obj.Spec.Limits == nil {
+		return nil, nil
+	}
+
+	name := obj.GetNameWithPrefix(r.Prefix)
+	limitRange := &corev1.LimitRange{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: name,
+		},
+	}
+
+	limitRange.Spec = *obj.Spec.Limits.DeepCopy()
+
+	limitRange.Labels = make(map[string]string)
+
+	r.applyMandatoryLabels("limitrange", "quota", "limitrange", limitRange.Labels)
+	r.applyProjectLabels(obj, limitRange.Labels)
 
-		if role.Labels == nil {
-			role.Labels = make(map[string]string)
+	if err := applier.apply(ctx, limitRange); err != nil {
+		return nil, fmt.Errorf("failed to create or update limit range: %w", err)
+	}
+
+	return limitRange, nil
+}
+
+func (r *ProjectReconciler) reconcileRole(ctx context.Context, applier *childApplier, obj *mpasv1alpha1.Project, rules []rbacv1.PolicyRule) (*rbacv1.Role, error) {
+	if obj.Spec.RBAC != nil {
+		if err := r.validateAdditionalRules(obj.Spec.RBAC.AdditionalRules); err != nil {
+			return nil, err
 		}
 
-		r.applyMandatoryLabels("role", "rbac", "role", role.Labels)
+		rules = append(rules[:len(rules):len(rules)], obj.Spec.RBAC.AdditionalRules...)
+	}
 
-		return nil
-	})
-	if err != nil {
+	name := obj.GetNameWithPrefix(r.Prefix)
+	role := &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: name,
+		},
+	}
+
+	role.Rules = rules
+
+	role.Labels = make(map[string]string)
+
+	r.applyMandatoryLabels("role", "rbac", "role", role.Labels)
+	r.applyProjectLabels(obj, role.Labels)
+
+	if err := applier.apply(ctx, role); err != nil {
 		return nil, fmt.Errorf("failed to create or update role: %w", err)
 	}
 
 	return role, nil
 }
 
+// defaultProjectPolicyRules returns the rules granting access to the resources that are managed in a project
+// namespace when no discovery client is configured.
+func defaultProjectPolicyRules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{
+		{
+			APIGroups: []string{""},
+			Resources: []string{"secrets"},
+			Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+		},
+		{
+			APIGroups: []string{"mpas.ocm.software"},
+			Resources: []string{
+				"repositories",
+				"targets",
+				"productdeployments",
+				"productdeploymentgenerators",
+				"productdeploymentpipelines",
+			},
+			Verbs: []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+		},
+		{
+			APIGroups: []string{"delivery.ocm.software"},
+			Resources: []string{"componentsubscriptions", "componentversions", "localizations", "configurations"},
+			Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+		},
+		{
+			APIGroups: []string{"source.toolkit.fluxcd.io", "kustomize.toolkit.fluxcd.io"},
+			Resources: []string{"ocirepositories", "kustomizations"},
+			Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+		},
+	}
+}
+
 func (r *ProjectReconciler) reconcileRoleBindings(
 	ctx context.Context,
+	applier *childApplier,
 	obj *mpasv1alpha1.Project,
 	sa *corev1.ServiceAccount,
 ) ([]*rbacv1.RoleBinding, error) {
@@ -349,6 +556,10 @@ func (r *ProjectReconciler) reconcileRoleBindings(
 
 	cr := &rbacv1.ClusterRole{}
 	if err := r.Client.Get(ctx, key, cr); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: %s", errClusterRoleNotFound, r.ClusterRoleName)
+		}
+
 		return nil, fmt.Errorf("failed to get projects cluster role: %w", err)
 	}
 
@@ -359,35 +570,31 @@ func (r *ProjectReconciler) reconcileRoleBindings(
 		},
 	}
 
-	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, mpasRoleBinding, func() error {
-		if obj.GetNamespace() == r.DefaultNamespace && mpasRoleBinding.ObjectMeta.CreationTimestamp.IsZero() {
-			if err := controllerutil.SetOwnerReference(obj, mpasRoleBinding, r.Scheme); err != nil {
-				return fmt.Errorf("failed to set owner reference on namespace %s with error: %w", r.DefaultNamespace, err)
-			}
+	if obj.GetNamespace() == r.DefaultNamespace {
+		if err := controllerutil.SetOwnerReference(obj, mpasRoleBinding, r.Scheme); err != nil {
+			return nil, fmt.Errorf("failed to set owner reference on namespace %s with error: %w", r.DefaultNamespace, err)
 		}
+	}
 
-		mpasRoleBinding.Subjects = []rbacv1.Subject{
-			{
-				Kind:      "ServiceAccount",
-				Name:      sa.GetName(),
-				Namespace: sa.GetNamespace(),
-			},
-		}
+	mpasRoleBinding.Subjects = []rbacv1.Subject{
+		{
+			Kind:      "ServiceAccount",
+			Name:      sa.GetName(),
+			Namespace: sa.GetNamespace(),
+		},
+	}
 
-		mpasRoleBinding.RoleRef = rbacv1.RoleRef{
-			Kind:     "ClusterRole",
-			Name:     cr.GetName(),
-			APIGroup: "rbac.authorization.k8s.io",
-		}
+	mpasRoleBinding.RoleRef = rbacv1.RoleRef{
+		Kind:     "ClusterRole",
+		Name:     cr.GetName(),
+		APIGroup: "rbac.authorization.k8s.io",
+	}
 
-		if mpasRoleBinding.Labels == nil {
-			mpasRoleBinding.Labels = make(map[string]string)
-		}
-		r.applyMandatoryLabels("clusterrole", "rbac", "clusterrole", mpasRoleBinding.Labels)
+	mpasRoleBinding.Labels = make(map[string]string)
+	r.applyMandatoryLabels("clusterrole", "rbac", "clusterrole", mpasRoleBinding.Labels)
+	r.applyProjectLabels(obj, mpasRoleBinding.Labels)
 
-		return nil
-	})
-	if err != nil {
+	if err := applier.apply(ctx, mpasRoleBinding); err != nil {
 		return nil, fmt.Errorf("failed to create or update role binding: %w", err)
 	}
 
@@ -398,30 +605,25 @@ func (r *ProjectReconciler) reconcileRoleBindings(
 		},
 	}
 
-	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, projectRoleBindingCR, func() error {
-		projectRoleBindingCR.Subjects = []rbacv1.Subject{
-			{
-				Kind:      "ServiceAccount",
-				Name:      sa.GetName(),
-				Namespace: sa.GetNamespace(),
-			},
-		}
-
-		projectRoleBindingCR.RoleRef = rbacv1.RoleRef{
-			Kind:     "ClusterRole",
-			Name:     cr.GetName(),
-			APIGroup: "rbac.authorization.k8s.io",
-		}
+	projectRoleBindingCR.Subjects = []rbacv1.Subject{
+		{
+			Kind:      "ServiceAccount",
+			Name:      sa.GetName(),
+			Namespace: sa.GetNamespace(),
+		},
+	}
 
-		if projectRoleBindingCR.Labels == nil {
-			projectRoleBindingCR.Labels = make(map[string]string)
-		}
-		r.applyMandatoryLabels("clusterrole", "rbac", "clusterrole", projectRoleBindingCR.Labels)
+	projectRoleBindingCR.RoleRef = rbacv1.RoleRef{
+		Kind:     "ClusterRole",
+		Name:     cr.GetName(),
+		APIGroup: "rbac.authorization.k8s.io",
+	}
 
-		return nil
-	})
+	projectRoleBindingCR.Labels = make(map[string]string)
+	r.applyMandatoryLabels("clusterrole", "rbac", "clusterrole", projectRoleBindingCR.Labels)
+	r.applyProjectLabels(obj, projectRoleBindingCR.Labels)
 
-	if err != nil {
+	if err := applier.apply(ctx, projectRoleBindingCR); err != nil {
 		return nil, fmt.Errorf("failed to create or update role binding: %w", err)
 	}
 
@@ -432,37 +634,32 @@ func (r *ProjectReconciler) reconcileRoleBindings(
 		},
 	}
 
-	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, projectRoleBinding, func() error {
-		projectRoleBinding.Subjects = []rbacv1.Subject{
-			{
-				Kind:      "ServiceAccount",
-				Name:      sa.GetName(),
-				Namespace: sa.GetNamespace(),
-			},
-		}
-
-		projectRoleBinding.RoleRef = rbacv1.RoleRef{
-			Kind:     "Role",
-			Name:     name,
-			APIGroup: "rbac.authorization.k8s.io",
-		}
+	projectRoleBinding.Subjects = []rbacv1.Subject{
+		{
+			Kind:      "ServiceAccount",
+			Name:      sa.GetName(),
+			Namespace: sa.GetNamespace(),
+		},
+	}
 
-		if projectRoleBinding.Labels == nil {
-			projectRoleBinding.Labels = make(map[string]string)
-		}
-		r.applyMandatoryLabels("role", "rbac", "role", projectRoleBinding.Labels)
+	projectRoleBinding.RoleRef = rbacv1.RoleRef{
+		Kind:     "Role",
+		Name:     name,
+		APIGroup: "rbac.authorization.k8s.io",
+	}
 
-		return nil
-	})
+	projectRoleBinding.Labels = make(map[string]string)
+	r.applyMandatoryLabels("role", "rbac", "role", projectRoleBinding.Labels)
+	r.applyProjectLabels(obj, projectRoleBinding.Labels)
 
-	if err != nil {
+	if err := applier.apply(ctx, projectRoleBinding); err != nil {
 		return nil, fmt.Errorf("failed to create or update role binding: %w", err)
 	}
 
 	return []*rbacv1.RoleBinding{mpasRoleBinding, projectRoleBindingCR, projectRoleBinding}, nil
 }
 
-func (r *ProjectReconciler) reconcileRepository(ctx context.Context, obj *mpasv1alpha1.Project) (*gcv1alpha1.Repository, error) {
+func (r *ProjectReconciler) reconcileRepository(ctx context.Context, applier *childApplier, obj *mpasv1alpha1.Project) (*gcv1alpha1.Repository, error) {
 	name := obj.GetNameWithPrefix(r.Prefix)
 	repo := &gcv1alpha1.Repository{
 		ObjectMeta: metav1.ObjectMeta{
@@ -471,32 +668,28 @@ func (r *ProjectReconciler) reconcileRepository(ctx context.Context, obj *mpasv1
 		},
 	}
 
-	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, repo, func() error {
-		if obj.GetNamespace() == r.DefaultNamespace && repo.ObjectMeta.CreationTimestamp.IsZero() {
-			if err := controllerutil.SetOwnerReference(obj, repo, r.Scheme); err != nil {
-				return fmt.Errorf("failed to set owner reference on namespace: %w", err)
-			}
+	if obj.GetNamespace() == r.DefaultNamespace {
+		if err := controllerutil.SetOwnerReference(obj, repo, r.Scheme); err != nil {
+			return nil, fmt.Errorf("failed to set owner reference on namespace: %w", err)
 		}
+	}
 
-		// obj.Spec.Git matches the Repository spec, so we can just assign it.
-		repo.Spec = obj.Spec.Git
+	// obj.Spec.Git matches the Repository spec, so we can just assign it.
+	repo.Spec = obj.Spec.Git
 
-		if repo.Spec.CommitTemplate == nil {
-			repo.Spec.CommitTemplate = &gcv1alpha1.CommitTemplate{
-				Name:    r.DefaultCommitTemplate.Name,
-				Email:   r.DefaultCommitTemplate.Email,
-				Message: r.DefaultCommitTemplate.Message,
-			}
+	if repo.Spec.CommitTemplate == nil {
+		repo.Spec.CommitTemplate = &gcv1alpha1.CommitTemplate{
+			Name:    r.DefaultCommitTemplate.Name,
+			Email:   r.DefaultCommitTemplate.Email,
+			Message: r.DefaultCommitTemplate.Message,
 		}
+	}
 
-		if repo.Labels == nil {
-			repo.Labels = make(map[string]string)
-		}
-		r.applyMandatoryLabels("repository", "manager", "repository", repo.Labels)
+	repo.Labels = make(map[string]string)
+	r.applyMandatoryLabels("repository", "manager", "repository", repo.Labels)
+	r.applyProjectLabels(obj, repo.Labels)
 
-		return nil
-	})
-	if err != nil {
+	if err := applier.apply(ctx, repo); err != nil {
 		return nil, fmt.Errorf("failed to create or update repository: %w", err)
 	}
 
@@ -505,6 +698,7 @@ func (r *ProjectReconciler) reconcileRepository(ctx context.Context, obj *mpasv1
 
 func (r *ProjectReconciler) reconcileFluxGitRepository(
 	ctx context.Context,
+	applier *childApplier,
 	obj *mpasv1alpha1.Project,
 	repo *gcv1alpha1.Repository,
 ) (*sourcev1.GitRepository, error) {
@@ -517,41 +711,70 @@ func (r *ProjectReconciler) reconcileFluxGitRepository(
 		},
 	}
 
-	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, gitRepo, func() error {
-		if obj.GetNamespace() == r.DefaultNamespace && gitRepo.ObjectMeta.CreationTimestamp.IsZero() {
-			if err := controllerutil.SetOwnerReference(obj, gitRepo, r.Scheme); err != nil {
-				return fmt.Errorf("failed to set owner reference on namespace: %w", err)
-			}
+	if obj.GetNamespace() == r.DefaultNamespace {
+		if err := controllerutil.SetOwnerReference(obj, gitRepo, r.Scheme); err != nil {
+			return nil, fmt.Errorf("failed to set owner reference on namespace: %w", err)
 		}
+	}
 
-		gitRepo.Spec.URL = repo.GetRepositoryURL()
-		gitRepo.Spec.Reference = &sourcev1.GitRepositoryRef{
-			Branch: repo.Spec.DefaultBranch,
-		}
-		gitRepo.Spec.SecretRef = (*meta.LocalObjectReference)(&repo.Spec.Credentials.SecretRef)
-		gitRepo.Spec.Interval = obj.Spec.Flux.Interval
+	gitRepo.Spec.URL = repo.GetRepositoryURL()
+	gitRepo.Spec.Reference = &sourcev1.GitRepositoryRef{
+		Branch: repo.Spec.DefaultBranch,
+	}
+	gitRepo.Spec.SecretRef = (*meta.LocalObjectReference)(&repo.Spec.Credentials.SecretRef)
+	gitRepo.Spec.Interval = obj.Spec.Flux.Interval
+	gitRepo.Spec.Suspend = obj.Spec.Suspend
 
-		if gitRepo.Labels == nil {
-			gitRepo.Labels = make(map[string]string)
-		}
-		r.applyMandatoryLabels("gitrepository", "manager", "gitrepository", gitRepo.Labels)
+	gitRepo.Labels = make(map[string]string)
+	r.applyMandatoryLabels("gitrepository", "manager", "gitrepository", gitRepo.Labels)
+	r.applyProjectLabels(obj, gitRepo.Labels)
 
-		return nil
-	})
-	if err != nil {
+	if err := applier.apply(ctx, gitRepo); err != nil {
 		return nil, fmt.Errorf("failed to create or update repository: %w", err)
 	}
 
 	return gitRepo, nil
 }
 
-func (r *ProjectReconciler) reconcileFluxKustomizations(ctx context.Context, obj *mpasv1alpha1.Project) ([]*kustomizev1.Kustomization, error) {
+func (r *ProjectReconciler) reconcileFluxKustomizations(ctx context.Context, applier *childApplier, obj *mpasv1alpha1.Project) ([]*kustomizev1.Kustomization, error) {
 	prefixedName := obj.GetNameWithPrefix(r.Prefix)
-	paths := []string{"subscriptions", "targets", "products", "generators"}
-	kustomizations := make([]*kustomizev1.Kustomization, 0)
+	specs := obj.GetFluxKustomizations()
+	kustomizations := make([]*kustomizev1.Kustomization, 0, len(specs))
+
+	names := make(map[string]struct{}, len(specs))
+	for _, spec := range specs {
+		names[spec.Name] = struct{}{}
+	}
+
+	for _, spec := range specs {
+		for _, dep := range spec.DependsOn {
+			if _, ok := names[dep]; !ok {
+				return nil, fmt.Errorf("kustomization %s depends on unknown kustomization %s", spec.Name, dep)
+			}
+		}
+	}
+
+	for _, spec := range specs {
+		name := fmt.Sprintf("%s-%s", prefixedName, spec.Name)
+
+		path := spec.Path
+		if path == "" {
+			path = spec.Name
+		}
+
+		interval := obj.Spec.Flux.Interval
+		if spec.Interval != nil {
+			interval = *spec.Interval
+		}
+
+		dependsOn := make([]meta.NamespacedObjectReference, 0, len(spec.DependsOn))
+		for _, dep := range spec.DependsOn {
+			dependsOn = append(dependsOn, meta.NamespacedObjectReference{
+				Name:      fmt.Sprintf("%s-%s", prefixedName, dep),
+				Namespace: r.DefaultNamespace,
+			})
+		}
 
-	for _, path := range paths {
-		name := fmt.Sprintf("%s-%s", prefixedName, path)
 		kustomization := &kustomizev1.Kustomization{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      name,
@@ -559,31 +782,30 @@ func (r *ProjectReconciler) reconcileFluxKustomizations(ctx context.Context, obj
 			},
 		}
 
-		_, err := controllerutil.CreateOrUpdate(ctx, r.Client, kustomization, func() error {
-			if obj.GetNamespace() == r.DefaultNamespace && kustomization.ObjectMeta.CreationTimestamp.IsZero() {
-				if err := controllerutil.SetOwnerReference(obj, kustomization, r.Scheme); err != nil {
-					return fmt.Errorf("failed to set owner reference on namespace: %w", err)
-				}
+		if obj.GetNamespace() == r.DefaultNamespace {
+			if err := controllerutil.SetOwnerReference(obj, kustomization, r.Scheme); err != nil {
+				return nil, fmt.Errorf("failed to set owner reference on namespace: %w", err)
 			}
+		}
 
-			kustomization.Spec.Path = path
-			kustomization.Spec.Interval = obj.Spec.Flux.Interval
-			kustomization.Spec.SourceRef = kustomizev1.CrossNamespaceSourceReference{
-				Kind:      "GitRepository",
-				Name:      prefixedName,
-				Namespace: obj.GetNamespace(),
-			}
-			kustomization.Spec.ServiceAccountName = ControllerName
-			kustomization.Spec.TargetNamespace = prefixedName
+		kustomization.Spec.Path = path
+		kustomization.Spec.Interval = interval
+		kustomization.Spec.Prune = spec.Prune
+		kustomization.Spec.Suspend = obj.Spec.Suspend
+		kustomization.Spec.DependsOn = dependsOn
+		kustomization.Spec.SourceRef = kustomizev1.CrossNamespaceSourceReference{
+			Kind:      "GitRepository",
+			Name:      prefixedName,
+			Namespace: obj.GetNamespace(),
+		}
+		kustomization.Spec.ServiceAccountName = ControllerName
+		kustomization.Spec.TargetNamespace = prefixedName
 
-			if kustomization.Labels == nil {
-				kustomization.Labels = make(map[string]string)
-			}
-			r.applyMandatoryLabels("kustomization", "manager", "kustomization", kustomization.Labels)
+		kustomization.Labels = make(map[string]string)
+		r.applyMandatoryLabels("kustomization", "manager", "kustomization", kustomization.Labels)
+		r.applyProjectLabels(obj, kustomization.Labels)
 
-			return nil
-		})
-		if err != nil {
+		if err := applier.apply(ctx, kustomization); err != nil {
 			return nil, fmt.Errorf("failed to create or update kustomization: %w", err)
 		}
 
@@ -593,30 +815,111 @@ func (r *ProjectReconciler) reconcileFluxKustomizations(ctx context.Context, obj
 	return kustomizations, nil
 }
 
-func (r *ProjectReconciler) finalize(ctx context.Context, obj *mpasv1alpha1.Project) error {
-	logger := log.FromContext(ctx)
+// suspendFluxResources suspends the Flux GitRepository and Kustomizations in the inventory of the Project,
+// so that Flux stops applying changes to the project namespace.
+func (r *ProjectReconciler) suspendFluxResources(ctx context.Context, obj *mpasv1alpha1.Project) error {
+	if obj.Status.Inventory == nil {
+		return nil
+	}
+
+	fluxResources, err := inventory.Filter(obj.Status.Inventory, func(m object.ObjMetadata) bool {
+		return m.GroupKind == schema.GroupKind{Group: sourcev1.GroupVersion.Group, Kind: sourcev1.GitRepositoryKind} ||
+			m.GroupKind == schema.GroupKind{Group: kustomizev1.GroupVersion.Group, Kind: kustomizev1.KustomizationKind}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to filter inventory: %w", err)
+	}
+
+	objects, err := inventory.List(fluxResources)
+	if err != nil {
+		return fmt.Errorf("failed to list inventory: %w", err)
+	}
+
+	suspendPatch := client.RawPatch(types.MergePatchType, []byte(`{"spec":{"suspend":true}}`))
+
 	var retErr error
-	if obj.Spec.Prune && obj.Status.Inventory != nil && obj.Status.Inventory.Entries != nil {
+	for _, o := range objects {
+		if err := r.Client.Patch(ctx, o, suspendPatch); err != nil && !apierrors.IsNotFound(err) {
+			retErr = errors.Join(retErr, fmt.Errorf("failed to suspend %s %s: %w", o.GetKind(), o.GetName(), err))
+		}
+	}
+
+	// The suspended resources no longer match their last applied desired state, so resuming them must not
+	// be mistaken for drift.
+	suspended := inventory.Entries(fluxResources)
+	for i, entry := range obj.Status.Inventory.Entries {
+		if _, ok := suspended[entry.ID]; ok {
+			obj.Status.Inventory.Entries[i].AppliedChecksum = ""
+		}
+	}
+
+	return retErr
+}
+
+func (r *ProjectReconciler) finalize(ctx context.Context, obj *mpasv1alpha1.Project) (ctrl.Result, error) {
+	if obj.GetAnnotations()[mpasv1alpha1.DeletionProtectionAnnotationKey] == "true" {
+		conditions.MarkTrue(obj, mpasv1alpha1.DeletionBlockedCondition, mpasv1alpha1.DeletionProtectedReason,
+			"deletion is blocked by the %s annotation, remove it to delete the project", mpasv1alpha1.DeletionProtectionAnnotationKey)
+
+		return ctrl.Result{}, nil
+	}
+
+	inUse, err := r.resourcesInUse(ctx, obj)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if len(inUse) > 0 {
+		conditions.MarkTrue(obj, mpasv1alpha1.DeletionBlockedCondition, mpasv1alpha1.ResourcesInUseReason,
+			"deletion is blocked by resources in the project namespace: %s, set the %s annotation to delete the project anyway",
+			strings.Join(inUse, ", "), mpasv1alpha1.ForceDeleteAnnotationKey)
+
+		return ctrl.Result{RequeueAfter: teardownRequeueInterval}, nil
+	}
+
+	conditions.Delete(obj, mpasv1alpha1.DeletionBlockedCondition)
+
+	if obj.Status.Inventory != nil && obj.Status.Inventory.Entries != nil {
 		objects, _ := inventory.List(obj.Status.Inventory)
 
-		for _, object := range objects {
-			if err := r.Client.Delete(ctx, object); err != nil {
-				if !apierrors.IsNotFound(err) {
-					logger.Error(err, "failed to delete object", "object", object)
-					retErr = errors.Join(retErr, err)
-				}
-			}
+		done, err := r.teardown(ctx, obj, objects)
+		if err != nil {
+			return ctrl.Result{}, err
 		}
 
-		if retErr != nil {
-			return retErr
+		if !done {
+			return ctrl.Result{RequeueAfter: teardownRequeueInterval}, nil
 		}
 	}
 
 	// Remove our finalizer from the list and update it
 	controllerutil.RemoveFinalizer(obj, mpasv1alpha1.ProjectFinalizer)
 
-	return nil
+	return ctrl.Result{}, nil
+}
+
+// orphan removes the owner reference to the Project from the given object, so that it isn't garbage
+// collected together with the Project.
+func (r *ProjectReconciler) orphan(ctx context.Context, obj *mpasv1alpha1.Project, object *unstructured.Unstructured) error {
+	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(object), object); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	refs := object.GetOwnerReferences()
+	kept := make([]metav1.OwnerReference, 0, len(refs))
+	for _, ref := range refs {
+		if ref.UID != obj.UID {
+			kept = append(kept, ref)
+		}
+	}
+
+	if len(kept) == len(refs) {
+		return nil
+	}
+
+	object.SetOwnerReferences(kept)
+
+	return client.IgnoreNotFound(r.Client.Update(ctx, object))
 }
 
 func (r *ProjectReconciler) prune(ctx context.Context, obj *mpasv1alpha1.Project, staleObjects []*unstructured.Unstructured) error {
@@ -624,15 +927,31 @@ func (r *ProjectReconciler) prune(ctx context.Context, obj *mpasv1alpha1.Project
 	var retErr error
 
 	if !obj.Spec.Prune {
+		// Nothing is considered for pruning, so previously skipped objects are no longer reported.
+		r.recordPruneSkipped(obj, nil)
+
 		return nil
 	}
 
+	var skipped []string
 	for _, object := range staleObjects {
 		if err := r.Client.Get(ctx, client.ObjectKeyFromObject(object), object); err != nil {
 			if !apierrors.IsNotFound(err) {
 				logger.Error(err, "failed to get object for deletion")
 				retErr = errors.Join(retErr, err)
 			}
+
+			continue
+		}
+
+		if pruneDisabled(object) {
+			skipped = append(skipped, ssa.FmtUnstructured(object))
+			if err := r.orphan(ctx, obj, object); err != nil {
+				logger.Error(err, "failed to orphan object", "object", object)
+				retErr = errors.Join(retErr, err)
+			}
+
+			continue
 		}
 
 		if err := r.Client.Delete(ctx, object); err != nil {
@@ -641,10 +960,17 @@ func (r *ProjectReconciler) prune(ctx context.Context, obj *mpasv1alpha1.Project
 		}
 	}
 
+	r.recordPruneSkipped(obj, skipped)
+
 	return retErr
 }
 
 func (r *ProjectReconciler) finalizeStatus(ctx context.Context, obj *mpasv1alpha1.Project, patcher *patch.SerialPatcher) error {
+	// Record the value of the reconciliation request if any.
+	if v, ok := meta.ReconcileAnnotationValue(obj.GetAnnotations()); ok {
+		obj.Status.SetLastHandledReconcileRequest(v)
+	}
+
 	// Remove the Reconciling condition and update the observed generation
 	// if the reconciliation was successful.
 	if conditions.IsTrue(obj, meta.ReadyCondition) {
@@ -655,8 +981,7 @@ func (r *ProjectReconciler) finalizeStatus(ctx context.Context, obj *mpasv1alpha
 	// Set the Reconciling reason to ProgressingWithRetry if the
 	// reconciliation has failed.
 	if conditions.IsFalse(obj, meta.ReadyCondition) &&
-		conditions.Has(obj, meta.ReconcilingCondition) &&
-		conditions.GetReason(obj, meta.ReconcilingCondition) != mpasv1alpha1.WaitingOnResourcesReason {
+		conditions.Has(obj, meta.ReconcilingCondition) {
 		rc := conditions.Get(obj, meta.ReconcilingCondition)
 		rc.Reason = meta.ProgressingWithRetryReason
 		conditions.Set(obj, rc)
@@ -673,19 +998,73 @@ func (r *ProjectReconciler) patch(ctx context.Context, obj *mpasv1alpha1.Project
 		meta.ReconcilingCondition,
 		meta.StalledCondition,
 	}
+	ownedConditions = append(ownedConditions, childConditionTypes...)
+	ownedConditions = append(ownedConditions,
+		mpasv1alpha1.DeletionBlockedCondition,
+		mpasv1alpha1.TerminatingCondition,
+		mpasv1alpha1.ForeignChangesCondition,
+	)
 	opts = append(opts,
 		patch.WithOwnedConditions{Conditions: ownedConditions},
 		patch.WithForceOverwriteConditions{},
 	)
 
-	if err := patcher.Patch(ctx, obj, opts...); err != nil {
-		return fmt.Errorf("failed to patch object: %w", err)
+	// The status contains the inventory in the form it is stored in, the loaded entries are restored
+	// after patching.
+	inv := obj.Status.Inventory
+	stored, err := r.inventoryStorage().Save(ctx, obj, inv)
+	if err != nil {
+		return fmt.Errorf("failed to store inventory: %w", err)
+	}
+
+	obj.Status.Inventory = stored
+	err = patcher.Patch(ctx, obj, opts...)
+	obj.Status.Inventory = inv
+
+	if err != nil {
+		// The project is gone once the finalizer of a deleted project has been removed.
+		if !obj.DeletionTimestamp.IsZero() {
+			err = kerrors.FilterOut(err, apierrors.IsNotFound)
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to patch object: %w", err)
+		}
+
+		return nil
+	}
+
+	if inv == nil {
+		return nil
+	}
+
+	// The ConfigMaps of previous inventories are only deleted once the status refers to the current one.
+	if !equality.Semantic.DeepEqual(inv.ConfigMapRef, stored.ConfigMapRef) {
+		if err := r.inventoryStorage().DeleteStale(ctx, obj, stored); err != nil {
+			return err
+		}
 	}
 
+	inv.ConfigMapRef = stored.ConfigMapRef
+
 	return nil
 }
 
-func (r *ProjectReconciler) reconcileCertificate(ctx context.Context, obj *mpasv1alpha1.Project) (*certmanagerv1.Certificate, error) {
+// apiReader returns the reader for objects that must be read from the API server, because the cache may not
+// have caught up with the changes of the controller yet.
+func (r *ProjectReconciler) apiReader() client.Reader {
+	if r.APIReader == nil {
+		return r.Client
+	}
+
+	return r.APIReader
+}
+
+func (r *ProjectReconciler) inventoryStorage() inventory.Storage {
+	return inventory.Storage{Client: r.Client, Reader: r.apiReader(), Threshold: r.InventoryThreshold}
+}
+
+func (r *ProjectReconciler) reconcileCertificate(ctx context.Context, applier *childApplier, obj *mpasv1alpha1.Project) (*certmanagerv1.Certificate, error) {
 	namespace := obj.GetNameWithPrefix(r.Prefix)
 	issuerName := r.IssuerName
 
@@ -697,25 +1076,27 @@ func (r *ProjectReconciler) reconcileCertificate(ctx context.Context, obj *mpasv
 		},
 	}
 
-	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, cert, func() error {
-		// Make sure the fields are all up-to-date
-		const keySize = 256
-		cert.Spec = certmanagerv1.CertificateSpec{
-			DNSNames:   []string{r.RegistryAddr},
-			SecretName: "ocm-registry-tls-certs",
-			IssuerRef: v1.ObjectReference{
-				Name:  issuerName,
-				Kind:  "ClusterIssuer",
-				Group: "cert-manager.io",
-			},
-			PrivateKey: &certmanagerv1.CertificatePrivateKey{
-				Algorithm: certmanagerv1.ECDSAKeyAlgorithm,
-				Size:      keySize,
-			},
-		}
+	// Make sure the fields are all up-to-date
+	const keySize = 256
+	cert.Spec = certmanagerv1.CertificateSpec{
+		DNSNames:   []string{r.RegistryAddr},
+		SecretName: "ocm-registry-tls-certs",
+		IssuerRef: v1.ObjectReference{
+			Name:  issuerName,
+			Kind:  "ClusterIssuer",
+			Group: "cert-manager.io",
+		},
+		PrivateKey: &certmanagerv1.CertificatePrivateKey{
+			Algorithm: certmanagerv1.ECDSAKeyAlgorithm,
+			Size:      keySize,
+		},
+	}
 
-		return nil
-	}); err != nil {
+	cert.Labels = make(map[string]string)
+	r.applyMandatoryLabels("certificate", "registry", "certificate", cert.Labels)
+	r.applyProjectLabels(obj, cert.Labels)
+
+	if err := applier.apply(ctx, cert); err != nil {
 		return nil, fmt.Errorf("failed to create certificate request in namespace: %w", err)
 	}
 
@@ -731,45 +1112,114 @@ func (r *ProjectReconciler) applyMandatoryLabels(name, component, instance strin
 	labels[labelName] = name
 }
 
-func (r *ProjectReconciler) reconcileInventory(ctx context.Context, obj *mpasv1alpha1.Project) ([]runtime.Object, error) {
+// applyProjectLabels marks a resource as owned by the given Project.
+func (r *ProjectReconciler) applyProjectLabels(obj *mpasv1alpha1.Project, labels map[string]string) {
+	labels[mpasv1alpha1.ProjectKey] = obj.Name
+	labels[mpasv1alpha1.ProjectNamespaceKey] = obj.Namespace
+}
+
+func (r *ProjectReconciler) reconcileInventory(ctx context.Context, applier *childApplier, obj *mpasv1alpha1.Project) ([]runtime.Object, error) {
 	var result []runtime.Object
 
-	ns, err := r.reconcileNamespace(ctx, obj)
+	if err := r.checkNamespaceOwnership(ctx, obj); err != nil {
+		reason := mpasv1alpha1.ReconciliationFailedReason
+		if errors.Is(err, errNamespaceOwnershipConflict) {
+			reason = mpasv1alpha1.NamespaceOwnershipConflictReason
+		}
+		r.markStalled(reason, obj, err)
+
+		return nil, fmt.Errorf("error checking namespace ownership: %w", err)
+	}
+
+	if err := r.checkExistingResources(ctx, obj); err != nil {
+		reason := mpasv1alpha1.ReconciliationFailedReason
+		if errors.Is(err, errExistingResourceConflict) {
+			reason = mpasv1alpha1.ExistingResourceConflictReason
+		}
+		r.markStalled(reason, obj, err)
+
+		return nil, fmt.Errorf("error checking existing resources: %w", err)
+	}
+
+	ns, err := r.reconcileNamespace(ctx, applier, obj)
 	if err != nil {
 		r.markStalled(mpasv1alpha1.NamespaceCreateOrUpdateFailedReason, obj, err)
 
 		return nil, fmt.Errorf("error reconciling namespace: %w", err)
 	}
 
-	sa, err := r.reconcileServiceAccount(ctx, obj)
+	sa, err := r.reconcileServiceAccount(ctx, applier, obj)
 	if err != nil {
 		r.markStalled(mpasv1alpha1.ServiceAccountCreateOrUpdateFailedReason, obj, err)
 
 		return nil, fmt.Errorf("error reconciling service account: %w", err)
 	}
 
-	role, err := r.reconcileRole(ctx, obj)
+	quota, err := r.reconcileResourceQuota(ctx, applier, obj)
+	if err != nil {
+		r.markStalled(mpasv1alpha1.ResourceQuotaCreateOrUpdateFailedReason, obj, err)
+
+		return nil, fmt.Errorf("error reconciling resource quota: %w", err)
+	}
+
+	limitRange, err := r.reconcileLimitRange(ctx, applier, obj)
+	if err != nil {
+		r.markStalled(mpasv1alpha1.LimitRangeCreateOrUpdateFailedReason, obj, err)
+
+		return nil, fmt.Errorf("error reconciling limit range: %w", err)
+	}
+
+	networkPolicies, err := r.reconcileNetworkPolicies(ctx, applier, obj)
+	if err != nil {
+		r.markStalled(mpasv1alpha1.NetworkPolicyCreateOrUpdateFailedReason, obj, err)
+
+		return nil, fmt.Errorf("error reconciling network policies: %w", err)
+	}
+
+	rules, err := r.projectPolicyRules(ctx)
 	if err != nil {
 		r.markStalled(mpasv1alpha1.RBACCreateOrUpdateFailedReason, obj, err)
 
+		return nil, fmt.Errorf("error discovering project role rules: %w", err)
+	}
+
+	role, err := r.reconcileRole(ctx, applier, obj, rules)
+	if err != nil {
+		reason := mpasv1alpha1.RBACCreateOrUpdateFailedReason
+		if errors.Is(err, errRBACRejected) {
+			reason = mpasv1alpha1.RBACRejectedReason
+		}
+		r.markStalled(reason, obj, err)
+
 		return nil, fmt.Errorf("error reconciling project namespace role: %w", err)
 	}
 
-	roleBindings, err := r.reconcileRoleBindings(ctx, obj, sa)
+	roleBindings, err := r.reconcileRoleBindings(ctx, applier, obj, sa)
 	if err != nil {
-		r.markStalled(mpasv1alpha1.RBACCreateOrUpdateFailedReason, obj, err)
+		reason := mpasv1alpha1.RBACCreateOrUpdateFailedReason
+		if errors.Is(err, errClusterRoleNotFound) {
+			reason = mpasv1alpha1.ClusterRoleNotFoundReason
+		}
+		r.markStalled(reason, obj, err)
 
 		return nil, fmt.Errorf("error reconciling role bindings: %w", err)
 	}
 
-	certificate, err := r.reconcileCertificate(ctx, obj)
+	memberRoleBindings, err := r.reconcileMemberRoleBindings(ctx, applier, obj, rules)
+	if err != nil {
+		r.markStalled(mpasv1alpha1.RBACCreateOrUpdateFailedReason, obj, err)
+
+		return nil, fmt.Errorf("error reconciling member role bindings: %w", err)
+	}
+
+	certificate, err := r.reconcileCertificate(ctx, applier, obj)
 	if err != nil {
 		r.markStalled(mpasv1alpha1.CertificateCreateOrUpdateFailedReason, obj, err)
 
 		return nil, fmt.Errorf("error reconciling certificate: %w", err)
 	}
 
-	repo, err := r.reconcileRepository(ctx, obj)
+	repo, err := r.reconcileRepository(ctx, applier, obj)
 	if err != nil {
 		r.markStalled(mpasv1alpha1.RepositoryCreateOrUpdateFailedReason, obj, err)
 
@@ -781,22 +1231,35 @@ func (r *ProjectReconciler) reconcileInventory(ctx context.Context, obj *mpasv1a
 		Namespace: repo.GetNamespace(),
 	}
 
-	gitRepo, err := r.reconcileFluxGitRepository(ctx, obj, repo)
+	gitRepo, err := r.reconcileFluxGitRepository(ctx, applier, obj, repo)
 	if err != nil {
 		r.markStalled(mpasv1alpha1.FluxGitRepositoryCreateOrUpdateFailedReason, obj, err)
 
 		return nil, fmt.Errorf("error reconciling flux git source: %w", err)
 	}
 
-	kustomizations, err := r.reconcileFluxKustomizations(ctx, obj)
+	kustomizations, err := r.reconcileFluxKustomizations(ctx, applier, obj)
 	if err != nil {
 		r.markStalled(mpasv1alpha1.FluxKustomizationsCreateOrUpdateFailedReason, obj, err)
 
 		return nil, fmt.Errorf("error reconciling flux kustomizations: %w", err)
 	}
 
+	log.FromContext(ctx).Info("server-side apply completed", "output", applier.changeSet.ToMap())
+	r.recordChangeSet(obj, applier)
+	r.recordDrift(obj, applier)
+	r.recordForeignChanges(obj, applier)
+
 	result = append(result, ns, sa, role, certificate, repo, gitRepo)
 
+	if quota != nil {
+		result = append(result, quota)
+	}
+
+	if limitRange != nil {
+		result = append(result, limitRange)
+	}
+
 	for _, k := range kustomizations {
 		result = append(result, k)
 	}
@@ -805,5 +1268,13 @@ func (r *ProjectReconciler) reconcileInventory(ctx context.Context, obj *mpasv1a
 		result = append(result, r)
 	}
 
+	for _, r := range memberRoleBindings {
+		result = append(result, r)
+	}
+
+	for _, p := range networkPolicies {
+		result = append(result, p)
+	}
+
 	return result, nil
 }
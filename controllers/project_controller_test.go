@@ -2,21 +2,42 @@ package controllers
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"testing"
+	"time"
 
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1"
 	"github.com/fluxcd/pkg/apis/meta"
 	"github.com/fluxcd/pkg/runtime/conditions"
+	"github.com/fluxcd/pkg/ssa"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	gcv1alpha1 "github.com/open-component-model/git-controller/apis/mpas/v1alpha1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	mpasv1alpha1 "github.com/open-component-model/mpas-project-controller/api/v1alpha1"
+	"github.com/open-component-model/mpas-project-controller/inventory"
 )
 
 func TestProjectReconciler(t *testing.T) {
@@ -54,6 +75,7 @@ func TestProjectReconciler(t *testing.T) {
 	client := env.FakeKubeClient(WithAddToScheme(mpasv1alpha1.AddToScheme), WithObjects(project, secret, cr))
 	controller := &ProjectReconciler{
 		Client:          client,
+		EventRecorder:   &mockEventRecorder{},
 		Scheme:          env.scheme,
 		ClusterRoleName: cr.Name,
 	}
@@ -66,7 +88,18 @@ func TestProjectReconciler(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	// Reconcile twice because the project will be requeued to wait for resources to be created.
+	err = client.Get(context.Background(), types.NamespacedName{
+		Namespace: project.Namespace,
+		Name:      project.Name,
+	}, project)
+	require.NoError(t, err)
+
+	// The children haven't been reconciled by their controllers yet.
+	assert.False(t, conditions.IsTrue(project, meta.ReadyCondition))
+	assert.True(t, conditions.Has(project, meta.ReconcilingCondition))
+
+	markChildrenReady(t, client)
+
 	_, err = controller.Reconcile(context.Background(), ctrl.Request{
 		NamespacedName: types.NamespacedName{
 			Namespace: project.Namespace,
@@ -82,6 +115,13 @@ func TestProjectReconciler(t *testing.T) {
 	require.NoError(t, err)
 
 	assert.True(t, conditions.IsTrue(project, meta.ReadyCondition))
+	assert.False(t, conditions.Has(project, meta.ReconcilingCondition))
+
+	// The inventory is recorded from the applied objects in a single reconcile.
+	require.NotNil(t, project.Status.Inventory)
+	entries := inventory.Entries(project.Status.Inventory)
+	assert.Equal(t, "v1alpha1", entries["_-test-project_mpas.ocm.software_Repository"].Version)
+	assert.Equal(t, "v1", entries["_-test-project__Namespace"].Version)
 }
 
 func TestProjectNamespaceAnnotation(t *testing.T) {
@@ -119,6 +159,7 @@ func TestProjectNamespaceAnnotation(t *testing.T) {
 	client := env.FakeKubeClient(WithAddToScheme(mpasv1alpha1.AddToScheme), WithObjects(project, secret, cr))
 	controller := &ProjectReconciler{
 		Client:          client,
+		EventRecorder:   &mockEventRecorder{},
 		Scheme:          env.scheme,
 		ClusterRoleName: cr.Name,
 		Prefix:          "mpas",
@@ -145,3 +186,2178 @@ func TestProjectNamespaceAnnotation(t *testing.T) {
 	_, ok := ns.Labels[mpasv1alpha1.ProjectKey]
 	assert.True(t, ok)
 }
+
+func TestProjectFluxKustomizations(t *testing.T) {
+	project := DefaultProject.DeepCopy()
+	project.Spec.Flux.Kustomizations = []mpasv1alpha1.FluxKustomization{
+		{Name: "configurations", Path: "./configurations", Prune: true},
+		{Name: "policies", DependsOn: []string{"configurations"}},
+	}
+	cr := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mpas-projects-clusterrole",
+		},
+	}
+
+	controllerutil.AddFinalizer(project, mpasv1alpha1.ProjectFinalizer)
+
+	client := env.FakeKubeClient(WithAddToScheme(mpasv1alpha1.AddToScheme), WithObjects(project, cr))
+	controller := &ProjectReconciler{
+		Client:           client,
+		EventRecorder:    &mockEventRecorder{},
+		Scheme:           env.scheme,
+		ClusterRoleName:  cr.Name,
+		Prefix:           "mpas",
+		DefaultNamespace: "mpas-system",
+	}
+
+	key := types.NamespacedName{
+		Namespace: project.Namespace,
+		Name:      project.Name,
+	}
+
+	_, err := controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	kustomizations := &kustomizev1.KustomizationList{}
+	require.NoError(t, client.List(context.Background(), kustomizations))
+	require.Len(t, kustomizations.Items, 2)
+
+	policies := &kustomizev1.Kustomization{}
+	require.NoError(t, client.Get(context.Background(), types.NamespacedName{Name: "mpas-test-project-policies", Namespace: "mpas-system"}, policies))
+	assert.Equal(t, "policies", policies.Spec.Path)
+	assert.False(t, policies.Spec.Prune)
+	assert.Equal(t, []meta.NamespacedObjectReference{{Name: "mpas-test-project-configurations", Namespace: "mpas-system"}}, policies.Spec.DependsOn)
+
+	configurations := &kustomizev1.Kustomization{}
+	require.NoError(t, client.Get(context.Background(), types.NamespacedName{Name: "mpas-test-project-configurations", Namespace: "mpas-system"}, configurations))
+	assert.Equal(t, "./configurations", configurations.Spec.Path)
+	assert.True(t, configurations.Spec.Prune)
+
+	// Removing a Kustomization from the list prunes it.
+	require.NoError(t, client.Get(context.Background(), key, project))
+	project.Spec.Flux.Kustomizations = project.Spec.Flux.Kustomizations[:1]
+	project.Generation++
+	require.NoError(t, client.Update(context.Background(), project))
+
+	_, err = controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	err = client.Get(context.Background(), types.NamespacedName{Name: "mpas-test-project-policies", Namespace: "mpas-system"}, policies)
+	assert.True(t, apierrors.IsNotFound(err))
+
+	markChildrenReady(t, client)
+
+	_, err = controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	require.NoError(t, client.Get(context.Background(), key, project))
+	assert.True(t, conditions.IsTrue(project, meta.ReadyCondition))
+}
+
+func TestProjectExistingResourcePolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   mpasv1alpha1.ExistingResourcePolicy
+		existing ctrlclient.Object
+		wantErr  bool
+	}{
+		{
+			name:   "fail policy stalls on an existing namespace",
+			policy: mpasv1alpha1.ExistingResourcePolicyFail,
+			existing: &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "mpas-test-project",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name:   "adopt if labelled policy adopts a labelled namespace",
+			policy: mpasv1alpha1.ExistingResourcePolicyAdoptIfLabelled,
+			existing: &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "mpas-test-project",
+					Labels: map[string]string{
+						mpasv1alpha1.ProjectKey: "test-project",
+					},
+				},
+			},
+		},
+		{
+			name:   "adopt if labelled policy stalls on an unlabelled repository",
+			policy: mpasv1alpha1.ExistingResourcePolicyAdoptIfLabelled,
+			existing: &gcv1alpha1.Repository{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "mpas-test-project",
+					Namespace: "mpas-system",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name:   "adopt policy adopts an existing git repository",
+			policy: mpasv1alpha1.ExistingResourcePolicyAdopt,
+			existing: &sourcev1.GitRepository{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "mpas-test-project",
+					Namespace: "mpas-system",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project := DefaultProject.DeepCopy()
+			project.Spec.ExistingResourcePolicy = tt.policy
+			cr := &rbacv1.ClusterRole{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "mpas-projects-clusterrole",
+				},
+			}
+
+			controllerutil.AddFinalizer(project, mpasv1alpha1.ProjectFinalizer)
+
+			client := env.FakeKubeClient(WithAddToScheme(mpasv1alpha1.AddToScheme), WithObjects(project, cr, tt.existing))
+			controller := &ProjectReconciler{
+				Client:           client,
+				EventRecorder:    &mockEventRecorder{},
+				Scheme:           env.scheme,
+				ClusterRoleName:  cr.Name,
+				Prefix:           "mpas",
+				DefaultNamespace: "mpas-system",
+			}
+
+			key := types.NamespacedName{
+				Namespace: project.Namespace,
+				Name:      project.Name,
+			}
+
+			_, err := controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+			require.NoError(t, client.Get(context.Background(), key, project))
+
+			if !tt.wantErr {
+				require.NoError(t, err)
+				assert.False(t, conditions.IsStalled(project))
+
+				return
+			}
+
+			require.Error(t, err)
+			assert.True(t, conditions.IsStalled(project))
+			assert.Equal(t, mpasv1alpha1.ExistingResourceConflictReason, conditions.GetReason(project, meta.StalledCondition))
+
+			// The existing resource must not have been modified.
+			existing := tt.existing.DeepCopyObject().(ctrlclient.Object)
+			require.NoError(t, client.Get(context.Background(), ctrlclient.ObjectKeyFromObject(tt.existing), existing))
+			assert.Equal(t, tt.existing.GetLabels(), existing.GetLabels())
+		})
+	}
+}
+
+func TestProjectNamespaceOwnershipConflict(t *testing.T) {
+	project := DefaultProject.DeepCopy()
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mpas-test-project",
+			Labels: map[string]string{
+				mpasv1alpha1.ProjectKey:          "test-project",
+				mpasv1alpha1.ProjectNamespaceKey: "other-namespace",
+			},
+		},
+	}
+	cr := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mpas-projects-clusterrole",
+		},
+	}
+
+	controllerutil.AddFinalizer(project, mpasv1alpha1.ProjectFinalizer)
+
+	client := env.FakeKubeClient(WithAddToScheme(mpasv1alpha1.AddToScheme), WithObjects(project, ns, cr))
+	controller := &ProjectReconciler{
+		Client:           client,
+		EventRecorder:    &mockEventRecorder{},
+		Scheme:           env.scheme,
+		ClusterRoleName:  cr.Name,
+		Prefix:           "mpas",
+		DefaultNamespace: "mpas-system",
+	}
+
+	key := types.NamespacedName{
+		Namespace: project.Namespace,
+		Name:      project.Name,
+	}
+
+	_, err := controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.Error(t, err)
+
+	require.NoError(t, client.Get(context.Background(), key, project))
+	assert.True(t, conditions.IsStalled(project))
+	assert.Equal(t, mpasv1alpha1.NamespaceOwnershipConflictReason, conditions.GetReason(project, meta.StalledCondition))
+	assert.Contains(t, conditions.GetMessage(project, meta.StalledCondition), mpasv1alpha1.ProjectNamespaceKey+"=other-namespace")
+
+	// No child resources must have been created.
+	sa := &corev1.ServiceAccount{}
+	err = client.Get(context.Background(), types.NamespacedName{Name: "mpas-test-project", Namespace: "mpas-test-project"}, sa)
+	assert.True(t, apierrors.IsNotFound(err))
+
+	require.NoError(t, client.Get(context.Background(), types.NamespacedName{Name: ns.Name}, ns))
+	assert.Equal(t, "other-namespace", ns.Labels[mpasv1alpha1.ProjectNamespaceKey])
+}
+
+func TestProjectQuotaAndLimits(t *testing.T) {
+	project := DefaultProject.DeepCopy()
+	project.Spec.Quota = &corev1.ResourceQuotaSpec{
+		Hard: corev1.ResourceList{
+			corev1.ResourceLimitsCPU: resource.MustParse("4"),
+		},
+	}
+	project.Spec.Limits = &corev1.LimitRangeSpec{
+		Limits: []corev1.LimitRangeItem{
+			{
+				Type: corev1.LimitTypeContainer,
+				Default: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("500m"),
+				},
+			},
+		},
+	}
+	cr := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mpas-projects-clusterrole",
+		},
+	}
+
+	controllerutil.AddFinalizer(project, mpasv1alpha1.ProjectFinalizer)
+
+	client := env.FakeKubeClient(WithAddToScheme(mpasv1alpha1.AddToScheme), WithObjects(project, cr))
+	controller := &ProjectReconciler{
+		Client:           client,
+		EventRecorder:    &mockEventRecorder{},
+		Scheme:           env.scheme,
+		ClusterRoleName:  cr.Name,
+		Prefix:           "mpas",
+		DefaultNamespace: "mpas-system",
+	}
+
+	key := types.NamespacedName{
+		Namespace: project.Namespace,
+		Name:      project.Name,
+	}
+	childKey := types.NamespacedName{
+		Namespace: "mpas-test-project",
+		Name:      "mpas-test-project",
+	}
+
+	_, err := controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	quota := &corev1.ResourceQuota{}
+	require.NoError(t, client.Get(context.Background(), childKey, quota))
+	assert.True(t, quota.Spec.Hard.Name(corev1.ResourceLimitsCPU, resource.DecimalSI).Equal(resource.MustParse("4")))
+
+	limitRange := &corev1.LimitRange{}
+	require.NoError(t, client.Get(context.Background(), childKey, limitRange))
+	assert.Len(t, limitRange.Spec.Limits, 1)
+
+	require.NoError(t, client.Get(context.Background(), key, project))
+	entries := inventory.Entries(project.Status.Inventory)
+	assert.Equal(t, "v1", entries["mpas-test-project_mpas-test-project__ResourceQuota"].Version)
+	assert.Equal(t, "v1", entries["mpas-test-project_mpas-test-project__LimitRange"].Version)
+
+	// Clearing the quota prunes the ResourceQuota.
+	project.Spec.Quota = nil
+	project.Generation++
+	require.NoError(t, client.Update(context.Background(), project))
+
+	_, err = controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	err = client.Get(context.Background(), childKey, quota)
+	assert.True(t, apierrors.IsNotFound(err))
+	require.NoError(t, client.Get(context.Background(), childKey, limitRange))
+}
+
+func TestProjectNetworkPolicies(t *testing.T) {
+	project := DefaultProject.DeepCopy()
+	cr := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mpas-projects-clusterrole",
+		},
+	}
+
+	controllerutil.AddFinalizer(project, mpasv1alpha1.ProjectFinalizer)
+
+	client := env.FakeKubeClient(WithAddToScheme(mpasv1alpha1.AddToScheme), WithObjects(project, cr))
+	controller := &ProjectReconciler{
+		Client:           client,
+		EventRecorder:    &mockEventRecorder{},
+		Scheme:           env.scheme,
+		ClusterRoleName:  cr.Name,
+		Prefix:           "mpas",
+		DefaultNamespace: "mpas-system",
+		RegistryAddr:     "registry.ocm-system.svc.cluster.local:5000",
+	}
+
+	key := types.NamespacedName{
+		Namespace: project.Namespace,
+		Name:      project.Name,
+	}
+
+	_, err := controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	policies := &networkingv1.NetworkPolicyList{}
+	require.NoError(t, client.List(context.Background(), policies, ctrlclient.InNamespace("mpas-test-project")))
+	require.Len(t, policies.Items, 5)
+
+	registry := &networkingv1.NetworkPolicy{}
+	require.NoError(t, client.Get(context.Background(), types.NamespacedName{
+		Name:      "mpas-test-project-allow-registry",
+		Namespace: "mpas-test-project",
+	}, registry))
+	assert.Equal(t, "ocm-system", registry.Spec.Egress[0].To[0].NamespaceSelector.MatchLabels["kubernetes.io/metadata.name"])
+
+	// Switching to custom mode keeps the registry and mpas-system rules and adds the custom peers.
+	require.NoError(t, client.Get(context.Background(), key, project))
+	project.Spec.NetworkPolicy = &mpasv1alpha1.NetworkPolicySpec{
+		Mode: mpasv1alpha1.NetworkPolicyModeCustom,
+		Egress: []networkingv1.NetworkPolicyPeer{
+			{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0/8"}},
+		},
+	}
+	project.Generation++
+	require.NoError(t, client.Update(context.Background(), project))
+
+	_, err = controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	require.NoError(t, client.List(context.Background(), policies, ctrlclient.InNamespace("mpas-test-project")))
+	names := make([]string, 0, len(policies.Items))
+	for _, p := range policies.Items {
+		names = append(names, p.Name)
+	}
+	assert.ElementsMatch(t, []string{
+		"mpas-test-project-default-deny",
+		"mpas-test-project-allow-dns",
+		"mpas-test-project-allow-same-namespace",
+		"mpas-test-project-allow-registry",
+		"mpas-test-project-allow-mpas-system",
+		"mpas-test-project-allow-custom",
+	}, names)
+
+	// Disabling network policies prunes all of them.
+	require.NoError(t, client.Get(context.Background(), key, project))
+	project.Spec.NetworkPolicy.Mode = mpasv1alpha1.NetworkPolicyModeDisabled
+	project.Generation++
+	require.NoError(t, client.Update(context.Background(), project))
+
+	_, err = controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	require.NoError(t, client.List(context.Background(), policies, ctrlclient.InNamespace("mpas-test-project")))
+	assert.Empty(t, policies.Items)
+}
+
+func TestRegistryNamespace(t *testing.T) {
+	tests := []struct {
+		name      string
+		addr      string
+		namespace string
+		wantErr   bool
+	}{
+		{name: "fully qualified service", addr: "registry.ocm-system.svc.cluster.local", namespace: "ocm-system"},
+		{name: "fully qualified service with port", addr: "registry.ocm-system.svc.cluster.local:5000", namespace: "ocm-system"},
+		{name: "short service", addr: "registry.ocm-system.svc:5000", namespace: "ocm-system"},
+		{name: "custom cluster domain", addr: "registry.ocm-system.svc.corp.local:5000", namespace: "ocm-system"},
+		{name: "not a service", addr: "registry.ocm-system.example.com", wantErr: true},
+		{name: "external host", addr: "ghcr.io", wantErr: true},
+		{name: "host with port", addr: "registry.local:5000", wantErr: true},
+		{name: "service without namespace", addr: "registry", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			namespace, err := registryNamespace(tt.addr)
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.namespace, namespace)
+		})
+	}
+}
+
+func TestProjectMembers(t *testing.T) {
+	project := DefaultProject.DeepCopy()
+	project.Spec.Members = []mpasv1alpha1.ProjectMember{
+		{Kind: "User", Name: "alice@example.com", Role: mpasv1alpha1.ProjectRoleEditor},
+		{Kind: "Group", Name: "auditors", Role: mpasv1alpha1.ProjectRoleViewer},
+		{Kind: "ServiceAccount", Name: "ci", Namespace: "ci-system", Role: mpasv1alpha1.ProjectRoleAdmin},
+	}
+	cr := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mpas-projects-clusterrole",
+		},
+	}
+
+	controllerutil.AddFinalizer(project, mpasv1alpha1.ProjectFinalizer)
+
+	client := env.FakeKubeClient(WithAddToScheme(mpasv1alpha1.AddToScheme), WithObjects(project, cr))
+	controller := &ProjectReconciler{
+		Client:           client,
+		EventRecorder:    &mockEventRecorder{},
+		Scheme:           env.scheme,
+		ClusterRoleName:  cr.Name,
+		Prefix:           "mpas",
+		DefaultNamespace: "mpas-system",
+	}
+
+	key := types.NamespacedName{
+		Namespace: project.Namespace,
+		Name:      project.Name,
+	}
+
+	_, err := controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	for _, role := range []string{"admin", "editor", "viewer"} {
+		clusterRole := &rbacv1.ClusterRole{}
+		require.NoError(t, client.Get(context.Background(), types.NamespacedName{Name: "mpas-project-" + role}, clusterRole))
+	}
+
+	editors := &rbacv1.RoleBinding{}
+	require.NoError(t, client.Get(context.Background(), types.NamespacedName{
+		Name:      "mpas-test-project-members-editor",
+		Namespace: "mpas-test-project",
+	}, editors))
+	assert.Equal(t, "mpas-project-editor", editors.RoleRef.Name)
+	assert.Equal(t, []rbacv1.Subject{{Kind: "User", Name: "alice@example.com", APIGroup: rbacv1.GroupName}}, editors.Subjects)
+
+	admins := &rbacv1.RoleBinding{}
+	require.NoError(t, client.Get(context.Background(), types.NamespacedName{
+		Name:      "mpas-test-project-members-admin",
+		Namespace: "mpas-test-project",
+	}, admins))
+	assert.Equal(t, []rbacv1.Subject{{Kind: "ServiceAccount", Name: "ci", Namespace: "ci-system"}}, admins.Subjects)
+
+	// Removing the viewer prunes its RoleBinding.
+	require.NoError(t, client.Get(context.Background(), key, project))
+	project.Spec.Members = project.Spec.Members[:1]
+	project.Generation++
+	require.NoError(t, client.Update(context.Background(), project))
+
+	_, err = controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	viewers := &rbacv1.RoleBinding{}
+	err = client.Get(context.Background(), types.NamespacedName{
+		Name:      "mpas-test-project-members-viewer",
+		Namespace: "mpas-test-project",
+	}, viewers)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestProjectAdditionalRBACRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   []rbacv1.PolicyRule
+		wantErr bool
+	}{
+		{
+			name: "permitted rules are added to the project role",
+			rules: []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get", "list"}},
+			},
+		},
+		{
+			name: "verbs outside the allow-list are rejected",
+			rules: []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"delete"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "api groups outside the allow-list are rejected",
+			rules: []rbacv1.PolicyRule{
+				{APIGroups: []string{"*"}, Resources: []string{"configmaps"}, Verbs: []string{"get"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "resources outside the allow-list are rejected",
+			rules: []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "wildcard resources are rejected",
+			rules: []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"*"}, Verbs: []string{"get"}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project := DefaultProject.DeepCopy()
+			project.Spec.RBAC = &mpasv1alpha1.RBACSpec{AdditionalRules: tt.rules}
+			cr := &rbacv1.ClusterRole{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "mpas-projects-clusterrole",
+				},
+			}
+
+			controllerutil.AddFinalizer(project, mpasv1alpha1.ProjectFinalizer)
+
+			client := env.FakeKubeClient(WithAddToScheme(mpasv1alpha1.AddToScheme), WithObjects(project, cr))
+			controller := &ProjectReconciler{
+				Client:               client,
+				EventRecorder:        &mockEventRecorder{},
+				Scheme:               env.scheme,
+				ClusterRoleName:      cr.Name,
+				Prefix:               "mpas",
+				DefaultNamespace:     "mpas-system",
+				AllowedRBACAPIGroups: []string{"", "helm.toolkit.fluxcd.io"},
+				AllowedRBACVerbs:     []string{"get", "list", "watch"},
+				AllowedRBACResources: []string{"configmaps", "helmreleases"},
+			}
+
+			key := types.NamespacedName{
+				Namespace: project.Namespace,
+				Name:      project.Name,
+			}
+
+			_, err := controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+			require.NoError(t, client.Get(context.Background(), key, project))
+
+			role := &rbacv1.Role{}
+			roleErr := client.Get(context.Background(), types.NamespacedName{Name: "mpas-test-project", Namespace: "mpas-test-project"}, role)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Equal(t, mpasv1alpha1.RBACRejectedReason, conditions.GetReason(project, meta.StalledCondition))
+				assert.True(t, apierrors.IsNotFound(roleErr))
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.NoError(t, roleErr)
+			assert.Contains(t, role.Rules, tt.rules[0])
+		})
+	}
+}
+
+func TestValidateAdditionalRulesWildcardResources(t *testing.T) {
+	controller := &ProjectReconciler{
+		AllowedRBACAPIGroups: []string{"*"},
+		AllowedRBACVerbs:     []string{"*"},
+		AllowedRBACResources: []string{"*"},
+	}
+
+	// Wildcard resources are rejected even if the allow-list permits all resources.
+	for _, resource := range []string{"*", "*/status"} {
+		err := controller.validateAdditionalRules([]rbacv1.PolicyRule{
+			{APIGroups: []string{""}, Resources: []string{resource}, Verbs: []string{"get"}},
+		})
+		assert.ErrorIs(t, err, errRBACRejected, resource)
+	}
+
+	require.NoError(t, controller.validateAdditionalRules([]rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"configmaps"}, ResourceNames: []string{"settings"}, Verbs: []string{"get"}},
+	}))
+}
+
+func TestProjectDiscoveredRoleRules(t *testing.T) {
+	project := DefaultProject.DeepCopy()
+	cr := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mpas-projects-clusterrole",
+		},
+	}
+
+	controllerutil.AddFinalizer(project, mpasv1alpha1.ProjectFinalizer)
+
+	discoveryClient := &fakediscovery.FakeDiscovery{
+		Fake: &clienttesting.Fake{
+			Resources: []*metav1.APIResourceList{
+				{
+					GroupVersion: "mpas.ocm.software/v1alpha1",
+					APIResources: []metav1.APIResource{
+						{Name: "projects", Namespaced: true},
+						{Name: "targets", Namespaced: true},
+						{Name: "targets/status", Namespaced: true},
+						{Name: "productdeployments", Namespaced: true},
+						{Name: "widgets", Namespaced: true},
+					},
+				},
+				{
+					GroupVersion: "kustomize.toolkit.fluxcd.io/v1beta2",
+					APIResources: []metav1.APIResource{
+						{Name: "kustomizations", Namespaced: true},
+					},
+				},
+				{
+					GroupVersion: "kustomize.toolkit.fluxcd.io/v1",
+					APIResources: []metav1.APIResource{
+						{Name: "kustomizations", Namespaced: true},
+					},
+				},
+				{
+					GroupVersion: "source.toolkit.fluxcd.io/v1",
+					APIResources: []metav1.APIResource{
+						{Name: "gitrepositories", Namespaced: true},
+						{Name: "ocirepositories", Namespaced: true},
+					},
+				},
+			},
+		},
+	}
+
+	client := env.FakeKubeClient(WithAddToScheme(mpasv1alpha1.AddToScheme), WithObjects(project, cr))
+	controller := &ProjectReconciler{
+		Client:           client,
+		EventRecorder:    &mockEventRecorder{},
+		Scheme:           env.scheme,
+		ClusterRoleName:  cr.Name,
+		Prefix:           "mpas",
+		DefaultNamespace: "mpas-system",
+		DiscoveryClient:  discoveryClient,
+		ProjectRoleResources: []string{
+			"mpas.ocm.software/projects",
+			"mpas.ocm.software/targets",
+			"mpas.ocm.software/productdeployments",
+			"delivery.ocm.software/componentversions",
+			"source.toolkit.fluxcd.io/ocirepositories",
+			"kustomize.toolkit.fluxcd.io/kustomizations",
+		},
+	}
+
+	key := types.NamespacedName{
+		Namespace: project.Namespace,
+		Name:      project.Name,
+	}
+
+	_, err := controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	role := &rbacv1.Role{}
+	require.NoError(t, client.Get(context.Background(), types.NamespacedName{Name: "mpas-test-project", Namespace: "mpas-test-project"}, role))
+
+	verbs := []string{"get", "list", "watch", "create", "update", "patch", "delete"}
+	assert.Equal(t, []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: verbs},
+		{APIGroups: []string{"kustomize.toolkit.fluxcd.io"}, Resources: []string{"kustomizations"}, Verbs: verbs},
+		{APIGroups: []string{"mpas.ocm.software"}, Resources: []string{"productdeployments", "targets"}, Verbs: verbs},
+		{APIGroups: []string{"source.toolkit.fluxcd.io"}, Resources: []string{"ocirepositories"}, Verbs: verbs},
+	}, role.Rules)
+
+	// CRD changes of configured resources requeue all projects.
+	crd := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: "productdeployments.mpas.ocm.software"}}
+	assert.Equal(t, []reconcile.Request{{NamespacedName: key}}, controller.requestsForCRDChange(crd))
+
+	crd.Name = "widgets.mpas.ocm.software"
+	assert.Empty(t, controller.requestsForCRDChange(crd))
+
+	// Granting the whole group is an explicit opt-in, which also covers resources installed later on.
+	controller.ProjectRoleResources = append(controller.ProjectRoleResources, "mpas.ocm.software/*")
+	assert.Equal(t, []reconcile.Request{{NamespacedName: key}}, controller.requestsForCRDChange(crd))
+
+	_, err = controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	require.NoError(t, client.Get(context.Background(), types.NamespacedName{Name: "mpas-test-project", Namespace: "mpas-test-project"}, role))
+	assert.Contains(t, role.Rules, rbacv1.PolicyRule{
+		APIGroups: []string{"mpas.ocm.software"},
+		Resources: []string{"productdeployments", "targets", "widgets"},
+		Verbs:     verbs,
+	})
+}
+
+func TestProjectSuspend(t *testing.T) {
+	project := DefaultProject.DeepCopy()
+	cr := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mpas-projects-clusterrole",
+		},
+	}
+
+	controllerutil.AddFinalizer(project, mpasv1alpha1.ProjectFinalizer)
+
+	client := env.FakeKubeClient(WithAddToScheme(mpasv1alpha1.AddToScheme), WithObjects(project, cr))
+	controller := &ProjectReconciler{
+		Client:           client,
+		EventRecorder:    &mockEventRecorder{},
+		Scheme:           env.scheme,
+		ClusterRoleName:  cr.Name,
+		Prefix:           "mpas",
+		DefaultNamespace: "mpas-system",
+	}
+
+	key := types.NamespacedName{
+		Namespace: project.Namespace,
+		Name:      project.Name,
+	}
+	childKey := types.NamespacedName{
+		Namespace: "mpas-system",
+		Name:      "mpas-test-project",
+	}
+
+	_, err := controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	require.NoError(t, client.Get(context.Background(), key, project))
+	project.Spec.Suspend = true
+	project.Generation++
+	require.NoError(t, client.Update(context.Background(), project))
+
+	// Deleted children are not restored while the project is suspended.
+	sa := &corev1.ServiceAccount{}
+	require.NoError(t, client.Get(context.Background(), types.NamespacedName{Name: "mpas-test-project", Namespace: "mpas-test-project"}, sa))
+	require.NoError(t, client.Delete(context.Background(), sa))
+
+	_, err = controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	err = client.Get(context.Background(), types.NamespacedName{Name: "mpas-test-project", Namespace: "mpas-test-project"}, sa)
+	assert.True(t, apierrors.IsNotFound(err))
+
+	gitRepo := &sourcev1.GitRepository{}
+	require.NoError(t, client.Get(context.Background(), childKey, gitRepo))
+	assert.True(t, gitRepo.Spec.Suspend)
+
+	kustomization := &kustomizev1.Kustomization{}
+	require.NoError(t, client.Get(context.Background(), types.NamespacedName{Name: "mpas-test-project-targets", Namespace: "mpas-system"}, kustomization))
+	assert.True(t, kustomization.Spec.Suspend)
+
+	// Resuming the project resumes the Flux resources.
+	require.NoError(t, client.Get(context.Background(), key, project))
+	project.Spec.Suspend = false
+	project.Generation++
+	require.NoError(t, client.Update(context.Background(), project))
+
+	_, err = controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	require.NoError(t, client.Get(context.Background(), childKey, gitRepo))
+	assert.False(t, gitRepo.Spec.Suspend)
+	require.NoError(t, client.Get(context.Background(), types.NamespacedName{Name: "mpas-test-project-targets", Namespace: "mpas-system"}, kustomization))
+	assert.False(t, kustomization.Spec.Suspend)
+	require.NoError(t, client.Get(context.Background(), types.NamespacedName{Name: "mpas-test-project", Namespace: "mpas-test-project"}, sa))
+}
+
+func TestProjectReconcileRequest(t *testing.T) {
+	project := DefaultProject.DeepCopy()
+	project.Annotations = map[string]string{
+		meta.ReconcileRequestAnnotation: "2024-01-01T00:00:00Z",
+	}
+	cr := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mpas-projects-clusterrole",
+		},
+	}
+
+	controllerutil.AddFinalizer(project, mpasv1alpha1.ProjectFinalizer)
+
+	client := env.FakeKubeClient(WithAddToScheme(mpasv1alpha1.AddToScheme), WithObjects(project, cr))
+	controller := &ProjectReconciler{
+		Client:           client,
+		EventRecorder:    &mockEventRecorder{},
+		Scheme:           env.scheme,
+		ClusterRoleName:  cr.Name,
+		Prefix:           "mpas",
+		DefaultNamespace: "mpas-system",
+	}
+
+	key := types.NamespacedName{
+		Namespace: project.Namespace,
+		Name:      project.Name,
+	}
+
+	_, err := controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	require.NoError(t, client.Get(context.Background(), key, project))
+	assert.Equal(t, "2024-01-01T00:00:00Z", project.Status.LastHandledReconcileAt)
+}
+
+func TestProjectChildConditions(t *testing.T) {
+	project := DefaultProject.DeepCopy()
+	cr := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mpas-projects-clusterrole",
+		},
+	}
+
+	issuer := &certmanagerv1.ClusterIssuer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mpas-certificate-issuer",
+		},
+	}
+
+	controllerutil.AddFinalizer(project, mpasv1alpha1.ProjectFinalizer)
+
+	client := env.FakeKubeClient(WithAddToScheme(mpasv1alpha1.AddToScheme), WithObjects(project, cr, issuer))
+	controller := &ProjectReconciler{
+		Client:           client,
+		EventRecorder:    &mockEventRecorder{},
+		Scheme:           env.scheme,
+		ClusterRoleName:  cr.Name,
+		IssuerName:       issuer.Name,
+		Prefix:           "mpas",
+		DefaultNamespace: "mpas-system",
+	}
+
+	key := types.NamespacedName{
+		Namespace: project.Namespace,
+		Name:      project.Name,
+	}
+
+	_, err := controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	// Children without a status haven't been reconciled by their controllers yet.
+	require.NoError(t, client.Get(context.Background(), key, project))
+	assert.False(t, conditions.IsReady(project))
+	for _, c := range childConditionTypes {
+		assert.True(t, conditions.IsUnknown(project, c), c)
+	}
+	assert.Contains(t, conditions.GetMessage(project, mpasv1alpha1.SourceReadyCondition), "waiting to be reconciled")
+	assert.Contains(t, conditions.GetMessage(project, mpasv1alpha1.CertificateReadyCondition), "waiting for the Ready condition")
+
+	markChildrenReady(t, client)
+
+	_, err = controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	require.NoError(t, client.Get(context.Background(), key, project))
+	assert.True(t, conditions.IsReady(project))
+	for _, c := range childConditionTypes {
+		assert.True(t, conditions.IsTrue(project, c), c)
+	}
+
+	gitRepo := &sourcev1.GitRepository{}
+	require.NoError(t, client.Get(context.Background(), types.NamespacedName{Name: "mpas-test-project", Namespace: "mpas-system"}, gitRepo))
+	conditions.MarkStalled(gitRepo, "AuthenticationFailed", "failed to authenticate")
+	conditions.MarkFalse(gitRepo, meta.ReadyCondition, "AuthenticationFailed", "failed to authenticate")
+	require.NoError(t, client.Status().Update(context.Background(), gitRepo))
+
+	cert := &certmanagerv1.Certificate{}
+	require.NoError(t, client.Get(context.Background(), types.NamespacedName{Name: "ocm-registry-tls-certs", Namespace: "mpas-test-project"}, cert))
+	cert.Status.Conditions = []certmanagerv1.CertificateCondition{
+		{
+			Type:    certmanagerv1.CertificateConditionReady,
+			Status:  cmmeta.ConditionFalse,
+			Reason:  "Issuing",
+			Message: "issuing certificate",
+		},
+	}
+	require.NoError(t, client.Status().Update(context.Background(), cert))
+
+	_, err = controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	require.NoError(t, client.Get(context.Background(), key, project))
+	assert.True(t, conditions.IsTrue(project, mpasv1alpha1.RepositoryReadyCondition))
+	assert.True(t, conditions.IsFalse(project, mpasv1alpha1.SourceReadyCondition))
+	assert.Contains(t, conditions.GetMessage(project, mpasv1alpha1.SourceReadyCondition), "failed to authenticate")
+	assert.True(t, conditions.IsUnknown(project, mpasv1alpha1.CertificateReadyCondition))
+	assert.True(t, conditions.IsFalse(project, meta.ReadyCondition))
+	assert.Equal(t, meta.FailedReason, conditions.GetReason(project, meta.ReadyCondition))
+
+	// A missing issuer is reported as the reason the certificate isn't ready.
+	require.NoError(t, client.Delete(context.Background(), issuer))
+
+	_, err = controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	require.NoError(t, client.Get(context.Background(), key, project))
+	assert.True(t, conditions.IsFalse(project, mpasv1alpha1.CertificateReadyCondition))
+	assert.Equal(t, mpasv1alpha1.ClusterIssuerNotFoundReason, conditions.GetReason(project, mpasv1alpha1.CertificateReadyCondition))
+
+	issuer.ResourceVersion = ""
+	require.NoError(t, client.Create(context.Background(), issuer))
+
+	// Once the children recover, the project turns ready again.
+	require.NoError(t, client.Get(context.Background(), types.NamespacedName{Name: "mpas-test-project", Namespace: "mpas-system"}, gitRepo))
+	conditions.Delete(gitRepo, meta.StalledCondition)
+	conditions.MarkTrue(gitRepo, meta.ReadyCondition, meta.SucceededReason, "stored artifact")
+	require.NoError(t, client.Status().Update(context.Background(), gitRepo))
+
+	require.NoError(t, client.Get(context.Background(), types.NamespacedName{Name: "ocm-registry-tls-certs", Namespace: "mpas-test-project"}, cert))
+	cert.Status.Conditions[0].Status = cmmeta.ConditionTrue
+	require.NoError(t, client.Status().Update(context.Background(), cert))
+
+	_, err = controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	require.NoError(t, client.Get(context.Background(), key, project))
+	assert.True(t, conditions.IsReady(project))
+}
+
+// markChildrenReady emulates the controllers of the Repositories, GitRepositories, Kustomizations and
+// Certificates, which report them as ready once they reconciled them.
+func markChildrenReady(t *testing.T, client ctrlclient.Client) {
+	t.Helper()
+
+	ctx := context.Background()
+	ready := metav1.Condition{
+		Type:    meta.ReadyCondition,
+		Status:  metav1.ConditionTrue,
+		Reason:  meta.SucceededReason,
+		Message: "ready",
+	}
+
+	// The fake client doesn't set the generation, so at least the first generation is reported as observed.
+	observedGeneration := func(obj ctrlclient.Object) int64 {
+		return max(obj.GetGeneration(), 1)
+	}
+
+	repositories := &gcv1alpha1.RepositoryList{}
+	require.NoError(t, client.List(ctx, repositories))
+	for i := range repositories.Items {
+		repo := &repositories.Items[i]
+		repo.Status.ObservedGeneration = observedGeneration(repo)
+		apimeta.SetStatusCondition(&repo.Status.Conditions, ready)
+		require.NoError(t, client.Status().Update(ctx, repo))
+	}
+
+	gitRepositories := &sourcev1.GitRepositoryList{}
+	require.NoError(t, client.List(ctx, gitRepositories))
+	for i := range gitRepositories.Items {
+		gitRepo := &gitRepositories.Items[i]
+		gitRepo.Status.ObservedGeneration = observedGeneration(gitRepo)
+		apimeta.SetStatusCondition(&gitRepo.Status.Conditions, ready)
+		require.NoError(t, client.Status().Update(ctx, gitRepo))
+	}
+
+	kustomizations := &kustomizev1.KustomizationList{}
+	require.NoError(t, client.List(ctx, kustomizations))
+	for i := range kustomizations.Items {
+		kustomization := &kustomizations.Items[i]
+		kustomization.Status.ObservedGeneration = observedGeneration(kustomization)
+		apimeta.SetStatusCondition(&kustomization.Status.Conditions, ready)
+		require.NoError(t, client.Status().Update(ctx, kustomization))
+	}
+
+	certificates := &certmanagerv1.CertificateList{}
+	require.NoError(t, client.List(ctx, certificates))
+	for i := range certificates.Items {
+		cert := &certificates.Items[i]
+		cert.Status.Conditions = []certmanagerv1.CertificateCondition{
+			{Type: certmanagerv1.CertificateConditionReady, Status: cmmeta.ConditionTrue, Reason: "Ready"},
+		}
+		require.NoError(t, client.Status().Update(ctx, cert))
+	}
+}
+
+func TestProjectServerSideApply(t *testing.T) {
+	project := DefaultProject.DeepCopy()
+	cr := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mpas-projects-clusterrole",
+		},
+	}
+
+	controllerutil.AddFinalizer(project, mpasv1alpha1.ProjectFinalizer)
+
+	client := env.FakeKubeClient(WithAddToScheme(mpasv1alpha1.AddToScheme), WithObjects(project, cr))
+	controller := &ProjectReconciler{
+		Client:           client,
+		EventRecorder:    &mockEventRecorder{},
+		Scheme:           env.scheme,
+		ClusterRoleName:  cr.Name,
+		Prefix:           "mpas",
+		DefaultNamespace: "mpas-system",
+	}
+
+	newNamespace := func() *corev1.Namespace {
+		return &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "applied",
+				Labels: map[string]string{"app": "applied"},
+			},
+		}
+	}
+
+	applier := controller.newChildApplier(project)
+	require.NoError(t, applier.apply(context.Background(), newNamespace()))
+	require.NoError(t, applier.apply(context.Background(), newNamespace()))
+
+	ns := newNamespace()
+	ns.Labels["app"] = "reapplied"
+	require.NoError(t, applier.apply(context.Background(), ns))
+
+	changeSet := applier.changeSet
+
+	require.Len(t, changeSet.Entries, 3)
+	assert.Equal(t, ssa.CreatedAction, changeSet.Entries[0].Action)
+	assert.Equal(t, ssa.UnchangedAction, changeSet.Entries[1].Action)
+	assert.Equal(t, ssa.ConfiguredAction, changeSet.Entries[2].Action)
+	assert.Equal(t, "Namespace/applied", changeSet.Entries[0].Subject)
+
+	key := types.NamespacedName{
+		Namespace: project.Namespace,
+		Name:      project.Name,
+	}
+	childKey := types.NamespacedName{
+		Namespace: "mpas-test-project",
+		Name:      "mpas-test-project",
+	}
+
+	_, err := controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	// Fields of other managers are kept, while changes to the fields of the controller are reverted.
+	sa := &corev1.ServiceAccount{}
+	require.NoError(t, client.Get(context.Background(), childKey, sa))
+	sa.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "pull-secret"}}
+	require.NoError(t, client.Update(context.Background(), sa))
+
+	role := &rbacv1.Role{}
+	require.NoError(t, client.Get(context.Background(), childKey, role))
+	rules := role.Rules
+	role.Rules = nil
+	require.NoError(t, client.Update(context.Background(), role))
+
+	_, err = controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	require.NoError(t, client.Get(context.Background(), childKey, sa))
+	assert.Equal(t, []corev1.LocalObjectReference{{Name: "pull-secret"}}, sa.ImagePullSecrets)
+	require.NoError(t, client.Get(context.Background(), childKey, role))
+	assert.Equal(t, rules, role.Rules)
+}
+
+func TestProjectServerSideApplyLegacyFieldManager(t *testing.T) {
+	project := DefaultProject.DeepCopy()
+	project.Spec.Flux.Kustomizations = []mpasv1alpha1.FluxKustomization{
+		{Name: "configurations", Path: "./configurations"},
+	}
+	cr := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mpas-projects-clusterrole",
+		},
+	}
+
+	// A Kustomization created by the controller before it used server-side apply, which depended on a
+	// Kustomization that has since been removed from the project. The timeout was set by someone else.
+	legacy := &kustomizev1.Kustomization{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "mpas-test-project-configurations",
+			Namespace: "mpas-system",
+			ManagedFields: []metav1.ManagedFieldsEntry{
+				{
+					Manager:    "manager",
+					Operation:  metav1.ManagedFieldsOperationUpdate,
+					APIVersion: kustomizev1.GroupVersion.String(),
+					FieldsType: "FieldsV1",
+					FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:dependsOn":{},"f:interval":{},"f:path":{},"f:sourceRef":{}}}`)},
+				},
+				{
+					Manager:    "kubectl-edit",
+					Operation:  metav1.ManagedFieldsOperationUpdate,
+					APIVersion: kustomizev1.GroupVersion.String(),
+					FieldsType: "FieldsV1",
+					FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:timeout":{}}}`)},
+				},
+			},
+		},
+		Spec: kustomizev1.KustomizationSpec{
+			Path:      "./configurations",
+			DependsOn: []meta.NamespacedObjectReference{{Name: "mpas-test-project-removed", Namespace: "mpas-system"}},
+			Timeout:   &metav1.Duration{Duration: time.Minute},
+		},
+	}
+
+	controllerutil.AddFinalizer(project, mpasv1alpha1.ProjectFinalizer)
+
+	client := env.FakeKubeClient(WithAddToScheme(mpasv1alpha1.AddToScheme), WithObjects(project, cr, legacy))
+	controller := &ProjectReconciler{
+		Client:           client,
+		EventRecorder:    &mockEventRecorder{},
+		Scheme:           env.scheme,
+		ClusterRoleName:  cr.Name,
+		Prefix:           "mpas",
+		DefaultNamespace: "mpas-system",
+	}
+
+	key := types.NamespacedName{
+		Namespace: project.Namespace,
+		Name:      project.Name,
+	}
+
+	_, err := controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	// The fields of the legacy field manager are taken over, so the removed dependency is gone, while the
+	// fields of other managers are kept.
+	kustomization := &kustomizev1.Kustomization{}
+	require.NoError(t, client.Get(context.Background(), ctrlclient.ObjectKeyFromObject(legacy), kustomization))
+	assert.Empty(t, kustomization.Spec.DependsOn)
+	assert.Equal(t, &metav1.Duration{Duration: time.Minute}, kustomization.Spec.Timeout)
+
+	managers := make([]string, 0, len(kustomization.ManagedFields))
+	for _, entry := range kustomization.ManagedFields {
+		managers = append(managers, fmt.Sprintf("%s/%s", entry.Manager, entry.Operation))
+	}
+	assert.ElementsMatch(t, []string{"kubectl-edit/Update", ControllerName + "/Apply"}, managers)
+}
+
+func TestProjectDriftPolicy(t *testing.T) {
+	tests := []struct {
+		name          string
+		policy        mpasv1alpha1.DriftPolicy
+		wantCorrected bool
+	}{
+		{
+			name:          "correct drift",
+			policy:        mpasv1alpha1.DriftPolicyCorrect,
+			wantCorrected: true,
+		},
+		{
+			name:          "report drift",
+			policy:        mpasv1alpha1.DriftPolicyReport,
+			wantCorrected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project := DefaultProject.DeepCopy()
+			project.Generation = 1
+			project.Spec.DriftPolicy = tt.policy
+			cr := &rbacv1.ClusterRole{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "mpas-projects-clusterrole",
+				},
+			}
+
+			controllerutil.AddFinalizer(project, mpasv1alpha1.ProjectFinalizer)
+
+			client := env.FakeKubeClient(WithAddToScheme(mpasv1alpha1.AddToScheme), WithObjects(project, cr))
+			recorder := &mockEventRecorder{}
+			controller := &ProjectReconciler{
+				Client:           client,
+				EventRecorder:    recorder,
+				Scheme:           env.scheme,
+				ClusterRoleName:  cr.Name,
+				Prefix:           "mpas",
+				DefaultNamespace: "mpas-system",
+			}
+
+			key := types.NamespacedName{
+				Namespace: project.Namespace,
+				Name:      project.Name,
+			}
+			childKey := types.NamespacedName{
+				Namespace: "mpas-test-project",
+				Name:      "mpas-test-project",
+			}
+
+			_, err := controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+			require.NoError(t, err)
+			require.NoError(t, client.Get(context.Background(), key, project))
+			assert.Nil(t, project.Status.LastDrift)
+			assert.NotContains(t, recorder.reasons(), mpasv1alpha1.DriftDetectedReason)
+
+			role := &rbacv1.Role{}
+			require.NoError(t, client.Get(context.Background(), childKey, role))
+			rules := role.Rules
+			role.Rules = nil
+			require.NoError(t, client.Update(context.Background(), role))
+
+			_, err = controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+			require.NoError(t, err)
+
+			require.NoError(t, client.Get(context.Background(), key, project))
+			require.NotNil(t, project.Status.LastDrift)
+			assert.Equal(t, []string{"Role/mpas-test-project/mpas-test-project"}, project.Status.LastDrift.Resources)
+			assert.Equal(t, tt.wantCorrected, project.Status.LastDrift.Corrected)
+			assert.Contains(t, recorder.reasons(), mpasv1alpha1.DriftDetectedReason)
+
+			require.NoError(t, client.Get(context.Background(), childKey, role))
+			if tt.wantCorrected {
+				assert.Equal(t, rules, role.Rules)
+			} else {
+				assert.Empty(t, role.Rules)
+			}
+		})
+	}
+}
+
+func TestProjectDriftDesiredStateChange(t *testing.T) {
+	project := DefaultProject.DeepCopy()
+	project.Generation = 1
+	project.Spec.DriftPolicy = mpasv1alpha1.DriftPolicyReport
+	project.Spec.Members = []mpasv1alpha1.ProjectMember{
+		{
+			Kind: rbacv1.UserKind,
+			Name: "alice",
+			Role: mpasv1alpha1.ProjectRoleAdmin,
+		},
+	}
+	cr := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mpas-projects-clusterrole",
+		},
+	}
+
+	controllerutil.AddFinalizer(project, mpasv1alpha1.ProjectFinalizer)
+
+	client := env.FakeKubeClient(WithAddToScheme(mpasv1alpha1.AddToScheme), WithObjects(project, cr))
+	recorder := &mockEventRecorder{}
+	controller := &ProjectReconciler{
+		Client:           client,
+		EventRecorder:    recorder,
+		Scheme:           env.scheme,
+		ClusterRoleName:  cr.Name,
+		Prefix:           "mpas",
+		DefaultNamespace: "mpas-system",
+		RegistryAddr:     "registry.ocm-system.svc.cluster.local:5000",
+	}
+
+	key := types.NamespacedName{
+		Namespace: project.Namespace,
+		Name:      project.Name,
+	}
+
+	_, err := controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	// The desired state changes without a new generation of the Project, e.g. after a restart of the
+	// controller with different flags. The change is applied and not mistaken for drift.
+	controller.RegistryAddr = "registry.other-system.svc.cluster.local:5000"
+	recorder.events = nil
+
+	_, err = controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	require.NoError(t, client.Get(context.Background(), key, project))
+	assert.Nil(t, project.Status.LastDrift)
+	assert.NotContains(t, recorder.reasons(), mpasv1alpha1.DriftDetectedReason)
+	assert.Equal(t, []string{
+		mpasv1alpha1.ChangesAppliedReason + ": NetworkPolicy/mpas-test-project/mpas-test-project-allow-registry configured\n" +
+			"Certificate/mpas-test-project/ocm-registry-tls-certs configured",
+	}, recorder.events)
+
+	// Nothing is reported if nothing changed.
+	recorder.events = nil
+	_, err = controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	assert.Empty(t, recorder.events)
+
+	cert := &certmanagerv1.Certificate{}
+	require.NoError(t, client.Get(context.Background(), types.NamespacedName{
+		Namespace: "mpas-test-project",
+		Name:      "ocm-registry-tls-certs",
+	}, cert))
+	assert.Equal(t, []string{controller.RegistryAddr}, cert.Spec.DNSNames)
+}
+
+func TestProjectChildLabels(t *testing.T) {
+	project := DefaultProject.DeepCopy()
+	project.Spec.Members = []mpasv1alpha1.ProjectMember{
+		{
+			Kind: rbacv1.UserKind,
+			Name: "alice",
+			Role: mpasv1alpha1.ProjectRoleViewer,
+		},
+	}
+	cr := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mpas-projects-clusterrole",
+		},
+	}
+
+	controllerutil.AddFinalizer(project, mpasv1alpha1.ProjectFinalizer)
+
+	client := env.FakeKubeClient(WithAddToScheme(mpasv1alpha1.AddToScheme), WithObjects(project, cr))
+	controller := &ProjectReconciler{
+		Client:           client,
+		EventRecorder:    &mockEventRecorder{},
+		Scheme:           env.scheme,
+		ClusterRoleName:  cr.Name,
+		Prefix:           "mpas",
+		DefaultNamespace: "mpas-system",
+	}
+
+	key := types.NamespacedName{
+		Namespace: project.Namespace,
+		Name:      project.Name,
+	}
+
+	_, err := controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	var children []ctrlclient.Object
+	serviceAccounts := &corev1.ServiceAccountList{}
+	require.NoError(t, client.List(context.Background(), serviceAccounts, ctrlclient.InNamespace("mpas-test-project")))
+	for i := range serviceAccounts.Items {
+		children = append(children, &serviceAccounts.Items[i])
+	}
+
+	roles := &rbacv1.RoleList{}
+	require.NoError(t, client.List(context.Background(), roles, ctrlclient.InNamespace("mpas-test-project")))
+	for i := range roles.Items {
+		children = append(children, &roles.Items[i])
+	}
+
+	roleBindings := &rbacv1.RoleBindingList{}
+	require.NoError(t, client.List(context.Background(), roleBindings, ctrlclient.InNamespace("mpas-test-project")))
+	for i := range roleBindings.Items {
+		children = append(children, &roleBindings.Items[i])
+	}
+
+	networkPolicies := &networkingv1.NetworkPolicyList{}
+	require.NoError(t, client.List(context.Background(), networkPolicies, ctrlclient.InNamespace("mpas-test-project")))
+	for i := range networkPolicies.Items {
+		children = append(children, &networkPolicies.Items[i])
+	}
+
+	require.Len(t, roleBindings.Items, 3)
+	require.NotEmpty(t, networkPolicies.Items)
+
+	for _, child := range children {
+		assert.Equal(t, []reconcile.Request{{NamespacedName: key}}, controller.requestsForProjectChild(child), child.GetName())
+	}
+
+	assert.Empty(t, controller.requestsForProjectChild(&corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "unrelated",
+			Namespace: "mpas-test-project",
+		},
+	}))
+}
+
+func TestProjectSharedClusterObjects(t *testing.T) {
+	project := DefaultProject.DeepCopy()
+	controllerutil.AddFinalizer(project, mpasv1alpha1.ProjectFinalizer)
+
+	client := env.FakeKubeClient(WithAddToScheme(mpasv1alpha1.AddToScheme), WithObjects(project))
+	controller := &ProjectReconciler{
+		Client:           client,
+		EventRecorder:    &mockEventRecorder{},
+		Scheme:           env.scheme,
+		ClusterRoleName:  "mpas-projects-clusterrole",
+		IssuerName:       "mpas-certificate-issuer",
+		Prefix:           "mpas",
+		DefaultNamespace: "mpas-system",
+	}
+
+	key := types.NamespacedName{
+		Namespace: project.Namespace,
+		Name:      project.Name,
+	}
+
+	_, err := controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.Error(t, err)
+
+	require.NoError(t, client.Get(context.Background(), key, project))
+	assert.True(t, conditions.IsStalled(project))
+	assert.Equal(t, mpasv1alpha1.ClusterRoleNotFoundReason, conditions.GetReason(project, meta.StalledCondition))
+
+	cr := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mpas-projects-clusterrole",
+		},
+	}
+	require.NoError(t, client.Create(context.Background(), cr))
+
+	wantRequests := []reconcile.Request{{NamespacedName: key}}
+	assert.Equal(t, wantRequests, controller.requestsForClusterRole(cr))
+	assert.Empty(t, controller.requestsForClusterRole(&rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mpas-project-admin",
+		},
+	}))
+	assert.Equal(t, wantRequests, controller.requestsForClusterIssuer(&certmanagerv1.ClusterIssuer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mpas-certificate-issuer",
+		},
+	}))
+	assert.Empty(t, controller.requestsForClusterIssuer(&certmanagerv1.ClusterIssuer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "other-issuer",
+		},
+	}))
+
+	_, err = controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	require.NoError(t, client.Get(context.Background(), key, project))
+	assert.False(t, conditions.IsStalled(project))
+
+	markChildrenReady(t, client)
+
+	_, err = controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	require.NoError(t, client.Get(context.Background(), key, project))
+	assert.True(t, conditions.IsReady(project))
+}
+
+func TestProjectDeletionPolicy(t *testing.T) {
+	project := DefaultProject.DeepCopy()
+	project.UID = "test-project-uid"
+	project.Spec.DeletionPolicy = &mpasv1alpha1.DeletionPolicySpec{
+		Kinds: map[string]mpasv1alpha1.DeletionPolicy{
+			"Repository": mpasv1alpha1.DeletionPolicyOrphan,
+		},
+	}
+	project.Annotations = map[string]string{
+		mpasv1alpha1.DeletionProtectionAnnotationKey: "true",
+	}
+	cr := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mpas-projects-clusterrole",
+		},
+	}
+
+	controllerutil.AddFinalizer(project, mpasv1alpha1.ProjectFinalizer)
+
+	client := env.FakeKubeClient(WithAddToScheme(mpasv1alpha1.AddToScheme), WithObjects(project, cr))
+	controller := &ProjectReconciler{
+		Client:           client,
+		EventRecorder:    &mockEventRecorder{},
+		Scheme:           env.scheme,
+		ClusterRoleName:  cr.Name,
+		Prefix:           "mpas",
+		DefaultNamespace: project.Namespace,
+	}
+
+	key := types.NamespacedName{
+		Namespace: project.Namespace,
+		Name:      project.Name,
+	}
+	childKey := types.NamespacedName{
+		Namespace: project.Namespace,
+		Name:      "mpas-test-project",
+	}
+
+	_, err := controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	repository := &gcv1alpha1.Repository{}
+	require.NoError(t, client.Get(context.Background(), childKey, repository))
+	require.Len(t, repository.OwnerReferences, 1)
+
+	// The project isn't finalized while it is protected.
+	require.NoError(t, client.Get(context.Background(), key, project))
+	require.NoError(t, client.Delete(context.Background(), project))
+
+	_, err = controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	require.NoError(t, client.Get(context.Background(), key, project))
+	assert.True(t, conditions.IsTrue(project, mpasv1alpha1.DeletionBlockedCondition))
+	assert.Equal(t, mpasv1alpha1.DeletionProtectedReason, conditions.GetReason(project, mpasv1alpha1.DeletionBlockedCondition))
+	assert.NoError(t, client.Get(context.Background(), types.NamespacedName{Name: "mpas-test-project"}, &corev1.Namespace{}))
+
+	// Once the protection is lifted, the project resources are deleted or orphaned according to their policy.
+	delete(project.Annotations, mpasv1alpha1.DeletionProtectionAnnotationKey)
+	require.NoError(t, client.Update(context.Background(), project))
+
+	_, err = controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	assert.True(t, apierrors.IsNotFound(client.Get(context.Background(), key, project)))
+	assert.True(t, apierrors.IsNotFound(client.Get(context.Background(), types.NamespacedName{Name: "mpas-test-project"}, &corev1.Namespace{})))
+	assert.True(t, apierrors.IsNotFound(client.Get(context.Background(), childKey, &sourcev1.GitRepository{})))
+
+	require.NoError(t, client.Get(context.Background(), childKey, repository))
+	assert.Empty(t, repository.OwnerReferences)
+}
+
+func TestProjectStagedTeardown(t *testing.T) {
+	project := DefaultProject.DeepCopy()
+	cr := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mpas-projects-clusterrole",
+		},
+	}
+
+	controllerutil.AddFinalizer(project, mpasv1alpha1.ProjectFinalizer)
+
+	client := env.FakeKubeClient(WithAddToScheme(mpasv1alpha1.AddToScheme), WithObjects(project, cr))
+	controller := &ProjectReconciler{
+		Client:           client,
+		EventRecorder:    &mockEventRecorder{},
+		Scheme:           env.scheme,
+		ClusterRoleName:  cr.Name,
+		Prefix:           "mpas",
+		DefaultNamespace: "mpas-system",
+	}
+
+	key := types.NamespacedName{
+		Namespace: project.Namespace,
+		Name:      project.Name,
+	}
+	sourceKey := types.NamespacedName{
+		Namespace: "mpas-system",
+		Name:      "mpas-test-project",
+	}
+	kustomizationKey := types.NamespacedName{
+		Namespace: "mpas-system",
+		Name:      "mpas-test-project-subscriptions",
+	}
+
+	_, err := controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	// The kustomize-controller keeps the Kustomization until it has garbage collected its resources.
+	kustomization := &kustomizev1.Kustomization{}
+	require.NoError(t, client.Get(context.Background(), kustomizationKey, kustomization))
+	controllerutil.AddFinalizer(kustomization, "finalizers.fluxcd.io")
+	require.NoError(t, client.Update(context.Background(), kustomization))
+
+	require.NoError(t, client.Get(context.Background(), key, project))
+	require.NoError(t, client.Delete(context.Background(), project))
+
+	result, err := controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	assert.Equal(t, teardownRequeueInterval, result.RequeueAfter)
+
+	require.NoError(t, client.Get(context.Background(), key, project))
+	assert.True(t, conditions.IsTrue(project, mpasv1alpha1.TerminatingCondition))
+	assert.Contains(t, conditions.GetMessage(project, mpasv1alpha1.TerminatingCondition), "Kustomization/mpas-test-project-subscriptions")
+
+	require.NoError(t, client.Get(context.Background(), kustomizationKey, kustomization))
+	assert.False(t, kustomization.Spec.Suspend)
+	assert.NotNil(t, kustomization.DeletionTimestamp)
+
+	// The sources and the namespace are kept until the Kustomizations are gone.
+	assert.NoError(t, client.Get(context.Background(), sourceKey, &sourcev1.GitRepository{}))
+	assert.NoError(t, client.Get(context.Background(), sourceKey, &gcv1alpha1.Repository{}))
+	assert.NoError(t, client.Get(context.Background(), types.NamespacedName{Name: "mpas-test-project"}, &corev1.Namespace{}))
+
+	controllerutil.RemoveFinalizer(kustomization, "finalizers.fluxcd.io")
+	require.NoError(t, client.Update(context.Background(), kustomization))
+
+	_, err = controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	assert.True(t, apierrors.IsNotFound(client.Get(context.Background(), key, project)))
+	assert.True(t, apierrors.IsNotFound(client.Get(context.Background(), sourceKey, &sourcev1.GitRepository{})))
+	assert.True(t, apierrors.IsNotFound(client.Get(context.Background(), sourceKey, &gcv1alpha1.Repository{})))
+	assert.True(t, apierrors.IsNotFound(client.Get(context.Background(), types.NamespacedName{Name: "mpas-test-project"}, &corev1.Namespace{})))
+}
+
+func TestProjectDeletionBlockedWhileInUse(t *testing.T) {
+	project := DefaultProject.DeepCopy()
+	project.Spec.DeletionPolicy = &mpasv1alpha1.DeletionPolicySpec{
+		BlockWhileInUse: true,
+	}
+	cr := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mpas-projects-clusterrole",
+		},
+	}
+
+	productDeploymentKind := schema.GroupVersionKind{
+		Group:   "mpas.ocm.software",
+		Version: "v1alpha1",
+		Kind:    "ProductDeployment",
+	}
+	restMapper := apimeta.NewDefaultRESTMapper([]schema.GroupVersion{productDeploymentKind.GroupVersion()})
+	restMapper.Add(productDeploymentKind, apimeta.RESTScopeNamespace)
+	addProductDeploymentToScheme := func(s *runtime.Scheme) error {
+		s.AddKnownTypeWithName(productDeploymentKind, &unstructured.Unstructured{})
+		s.AddKnownTypeWithName(productDeploymentKind.GroupVersion().WithKind("ProductDeploymentList"), &unstructured.UnstructuredList{})
+
+		return nil
+	}
+
+	productDeployment := &unstructured.Unstructured{}
+	productDeployment.SetGroupVersionKind(productDeploymentKind)
+	productDeployment.SetName("podinfo")
+	productDeployment.SetNamespace("mpas-test-project")
+
+	controllerutil.AddFinalizer(project, mpasv1alpha1.ProjectFinalizer)
+
+	client := env.FakeKubeClient(
+		WithAddToScheme(mpasv1alpha1.AddToScheme),
+		WithAddToScheme(addProductDeploymentToScheme),
+		WithObjects(project, cr, productDeployment),
+		WithRESTMapper(restMapper),
+	)
+	controller := &ProjectReconciler{
+		Client:                client,
+		EventRecorder:         &mockEventRecorder{},
+		Scheme:                env.scheme,
+		ClusterRoleName:       cr.Name,
+		Prefix:                "mpas",
+		DefaultNamespace:      "mpas-system",
+		DeletionBlockingKinds: []string{"mpas.ocm.software/ProductDeployment", "mpas.ocm.software/Target"},
+	}
+
+	key := types.NamespacedName{
+		Namespace: project.Namespace,
+		Name:      project.Name,
+	}
+
+	_, err := controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	require.NoError(t, client.Get(context.Background(), key, project))
+	require.NoError(t, client.Delete(context.Background(), project))
+
+	_, err = controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	require.NoError(t, client.Get(context.Background(), key, project))
+	assert.True(t, conditions.IsTrue(project, mpasv1alpha1.DeletionBlockedCondition))
+	assert.Equal(t, mpasv1alpha1.ResourcesInUseReason, conditions.GetReason(project, mpasv1alpha1.DeletionBlockedCondition))
+	assert.Contains(t, conditions.GetMessage(project, mpasv1alpha1.DeletionBlockedCondition), "ProductDeployment/podinfo")
+	assert.NoError(t, client.Get(context.Background(), types.NamespacedName{Name: "mpas-test-project"}, &corev1.Namespace{}))
+
+	// The force delete annotation overrides the resources in use.
+	project.Annotations = map[string]string{
+		mpasv1alpha1.ForceDeleteAnnotationKey: "true",
+	}
+	require.NoError(t, client.Update(context.Background(), project))
+
+	_, err = controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	assert.True(t, apierrors.IsNotFound(client.Get(context.Background(), key, project)))
+	assert.True(t, apierrors.IsNotFound(client.Get(context.Background(), types.NamespacedName{Name: "mpas-test-project"}, &corev1.Namespace{})))
+}
+
+func TestProjectResourcesInUseAPIReader(t *testing.T) {
+	project := DefaultProject.DeepCopy()
+	project.Spec.DeletionPolicy = &mpasv1alpha1.DeletionPolicySpec{
+		BlockWhileInUse: true,
+	}
+
+	productDeploymentKind := schema.GroupVersionKind{
+		Group:   "mpas.ocm.software",
+		Version: "v1alpha1",
+		Kind:    "ProductDeployment",
+	}
+	restMapper := apimeta.NewDefaultRESTMapper([]schema.GroupVersion{productDeploymentKind.GroupVersion()})
+	restMapper.Add(productDeploymentKind, apimeta.RESTScopeNamespace)
+
+	client := env.FakeKubeClient(
+		WithAddToScheme(mpasv1alpha1.AddToScheme),
+		WithObjects(project),
+		WithRESTMapper(restMapper),
+	)
+	reader := &countingReader{
+		Reader:  client,
+		listErr: apierrors.NewForbidden(schema.GroupResource{Group: "mpas.ocm.software", Resource: "productdeployments"}, "", errors.New("no permission")),
+	}
+	controller := &ProjectReconciler{
+		Client:                client,
+		EventRecorder:         &mockEventRecorder{},
+		APIReader:             reader,
+		Scheme:                env.scheme,
+		Prefix:                "mpas",
+		DefaultNamespace:      "mpas-system",
+		DeletionBlockingKinds: []string{"mpas.ocm.software/ProductDeployment"},
+	}
+
+	// The resources are listed from the API server, so missing permissions fail instead of waiting for a cache.
+	_, err := controller.resourcesInUse(context.Background(), project)
+	assert.True(t, apierrors.IsForbidden(err))
+	assert.Equal(t, 1, reader.lists)
+
+	// Kinds that are no longer served don't block the deletion.
+	reader.listErr = &apimeta.NoKindMatchError{GroupKind: productDeploymentKind.GroupKind()}
+	inUse, err := controller.resourcesInUse(context.Background(), project)
+	require.NoError(t, err)
+	assert.Empty(t, inUse)
+}
+
+func TestProjectPruneDisabled(t *testing.T) {
+	project := DefaultProject.DeepCopy()
+	project.Spec.Members = []mpasv1alpha1.ProjectMember{
+		{
+			Kind: rbacv1.UserKind,
+			Name: "alice",
+			Role: mpasv1alpha1.ProjectRoleViewer,
+		},
+	}
+	cr := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mpas-projects-clusterrole",
+		},
+	}
+
+	controllerutil.AddFinalizer(project, mpasv1alpha1.ProjectFinalizer)
+
+	client := env.FakeKubeClient(WithAddToScheme(mpasv1alpha1.AddToScheme), WithObjects(project, cr))
+	recorder := &mockEventRecorder{}
+	controller := &ProjectReconciler{
+		Client:           client,
+		EventRecorder:    recorder,
+		Scheme:           env.scheme,
+		ClusterRoleName:  cr.Name,
+		Prefix:           "mpas",
+		DefaultNamespace: "mpas-system",
+	}
+
+	key := types.NamespacedName{
+		Namespace: project.Namespace,
+		Name:      project.Name,
+	}
+	roleBindingKey := types.NamespacedName{
+		Namespace: "mpas-test-project",
+		Name:      "mpas-test-project-members-viewer",
+	}
+	repositoryKey := types.NamespacedName{
+		Namespace: "mpas-system",
+		Name:      "mpas-test-project",
+	}
+
+	_, err := controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	roleBinding := &rbacv1.RoleBinding{}
+	require.NoError(t, client.Get(context.Background(), roleBindingKey, roleBinding))
+	roleBinding.Annotations = map[string]string{mpasv1alpha1.PruneAnnotationKey: mpasv1alpha1.PruneDisabledValue}
+	require.NoError(t, client.Update(context.Background(), roleBinding))
+
+	repository := &gcv1alpha1.Repository{}
+	require.NoError(t, client.Get(context.Background(), repositoryKey, repository))
+	repository.Annotations = map[string]string{mpasv1alpha1.PruneAnnotationKey: mpasv1alpha1.PruneDisabledValue}
+	require.NoError(t, client.Update(context.Background(), repository))
+
+	// The role binding is kept although the member was removed.
+	require.NoError(t, client.Get(context.Background(), key, project))
+	project.Spec.Members = nil
+	project.Generation++
+	require.NoError(t, client.Update(context.Background(), project))
+
+	_, err = controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	assert.NoError(t, client.Get(context.Background(), roleBindingKey, roleBinding))
+	require.NoError(t, client.Get(context.Background(), key, project))
+	assert.Equal(t, []string{"RoleBinding/mpas-test-project/mpas-test-project-members-viewer"}, project.Status.PruneSkipped)
+	assert.Contains(t, recorder.reasons(), mpasv1alpha1.PruneSkippedReason)
+
+	// Nothing is skipped once the role binding was orphaned.
+	recorder.events = nil
+	_, err = controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	require.NoError(t, client.Get(context.Background(), key, project))
+	assert.Empty(t, project.Status.PruneSkipped)
+	assert.NotContains(t, recorder.reasons(), mpasv1alpha1.PruneSkippedReason)
+
+	// The repository is kept when the project is deleted.
+	require.NoError(t, client.Delete(context.Background(), project))
+
+	_, err = controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	assert.True(t, apierrors.IsNotFound(client.Get(context.Background(), key, project)))
+	assert.True(t, apierrors.IsNotFound(client.Get(context.Background(), repositoryKey, &sourcev1.GitRepository{})))
+	assert.NoError(t, client.Get(context.Background(), repositoryKey, repository))
+}
+
+func TestProjectPruneToggledOff(t *testing.T) {
+	project := DefaultProject.DeepCopy()
+	project.Spec.Prune = false
+	project.Status.PruneSkipped = []string{"RoleBinding/mpas-test-project/mpas-test-project-members-viewer"}
+	cr := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mpas-projects-clusterrole",
+		},
+	}
+
+	controllerutil.AddFinalizer(project, mpasv1alpha1.ProjectFinalizer)
+
+	client := env.FakeKubeClient(WithAddToScheme(mpasv1alpha1.AddToScheme), WithObjects(project, cr))
+	controller := &ProjectReconciler{
+		Client:           client,
+		EventRecorder:    &mockEventRecorder{},
+		Scheme:           env.scheme,
+		ClusterRoleName:  cr.Name,
+		Prefix:           "mpas",
+		DefaultNamespace: "mpas-system",
+	}
+
+	key := types.NamespacedName{
+		Namespace: project.Namespace,
+		Name:      project.Name,
+	}
+
+	// Skipped objects of an earlier prune are no longer reported once pruning is turned off.
+	_, err := controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	require.NoError(t, client.Get(context.Background(), key, project))
+	assert.Empty(t, project.Status.PruneSkipped)
+}
+
+func TestRecordPruneSkipped(t *testing.T) {
+	recorder := &mockEventRecorder{}
+	controller := &ProjectReconciler{
+		EventRecorder: recorder,
+	}
+	project := DefaultProject.DeepCopy()
+
+	controller.recordPruneSkipped(project, []string{"Repository/mpas-system/b", "Repository/mpas-system/a"})
+	assert.Equal(t, []string{"Repository/mpas-system/a", "Repository/mpas-system/b"}, project.Status.PruneSkipped)
+	assert.True(t, recorder.called)
+
+	// The same objects are skipped again, e.g. while the teardown is retried.
+	recorder.called = false
+	controller.recordPruneSkipped(project, []string{"Repository/mpas-system/a", "Repository/mpas-system/b"})
+	assert.False(t, recorder.called)
+
+	controller.recordPruneSkipped(project, []string{"Repository/mpas-system/a"})
+	assert.Equal(t, []string{"Repository/mpas-system/a"}, project.Status.PruneSkipped)
+	assert.True(t, recorder.called)
+
+	recorder.called = false
+	controller.recordPruneSkipped(project, nil)
+	assert.Empty(t, project.Status.PruneSkipped)
+	assert.False(t, recorder.called)
+}
+
+func TestProjectForeignChanges(t *testing.T) {
+	project := DefaultProject.DeepCopy()
+	project.Generation = 1
+	cr := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mpas-projects-clusterrole",
+		},
+	}
+
+	controllerutil.AddFinalizer(project, mpasv1alpha1.ProjectFinalizer)
+
+	client := env.FakeKubeClient(WithAddToScheme(mpasv1alpha1.AddToScheme), WithObjects(project, cr))
+	recorder := &mockEventRecorder{}
+	controller := &ProjectReconciler{
+		Client:           client,
+		EventRecorder:    recorder,
+		Scheme:           env.scheme,
+		ClusterRoleName:  cr.Name,
+		Prefix:           "mpas",
+		DefaultNamespace: "mpas-system",
+	}
+
+	key := types.NamespacedName{
+		Namespace: project.Namespace,
+		Name:      project.Name,
+	}
+
+	_, err := controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	require.NoError(t, client.Get(context.Background(), key, project))
+	entries := inventory.Entries(project.Status.Inventory)
+	namespaceEntry := entries["_mpas-test-project__Namespace"]
+	assert.NotEmpty(t, namespaceEntry.UID)
+	assert.NotEmpty(t, entries["mpas-system_mpas-test-project_source.toolkit.fluxcd.io_GitRepository"].Checksum)
+	assert.False(t, conditions.Has(project, mpasv1alpha1.ForeignChangesCondition))
+
+	// Another tool replaces the project namespace.
+	ns := &corev1.Namespace{}
+	require.NoError(t, client.Get(context.Background(), types.NamespacedName{Name: "mpas-test-project"}, ns))
+	require.NoError(t, client.Delete(context.Background(), ns))
+	require.NoError(t, client.Create(context.Background(), &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mpas-test-project",
+		},
+	}))
+
+	_, err = controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	require.NoError(t, client.Get(context.Background(), key, project))
+	assert.True(t, conditions.IsTrue(project, mpasv1alpha1.ForeignChangesCondition))
+	assert.Equal(t, mpasv1alpha1.ForeignRecreationReason, conditions.GetReason(project, mpasv1alpha1.ForeignChangesCondition))
+	assert.Contains(t, conditions.GetMessage(project, mpasv1alpha1.ForeignChangesCondition), "Namespace/mpas-test-project")
+	assert.Contains(t, recorder.reasons(), mpasv1alpha1.ForeignRecreationReason)
+	assert.NotEqual(t, namespaceEntry.UID, inventory.Entries(project.Status.Inventory)["_mpas-test-project__Namespace"].UID)
+
+	// The replacement is reported once, after that the new namespace is known.
+	_, err = controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	require.NoError(t, client.Get(context.Background(), key, project))
+	assert.False(t, conditions.Has(project, mpasv1alpha1.ForeignChangesCondition))
+
+	// Changes to the spec outside of the fields of the controller are reported as unexpected.
+	gitRepo := &sourcev1.GitRepository{}
+	require.NoError(t, client.Get(context.Background(), types.NamespacedName{Name: "mpas-test-project", Namespace: "mpas-system"}, gitRepo))
+	ignore := "/*"
+	gitRepo.Spec.Ignore = &ignore
+	require.NoError(t, client.Update(context.Background(), gitRepo))
+
+	applier := controller.newChildApplier(project)
+	u, err := applier.toUnstructured(gitRepo)
+	require.NoError(t, err)
+	require.NoError(t, applier.observe(context.Background(), gitRepo, u, ssa.UnchangedAction, true))
+	assert.Equal(t, []string{"GitRepository/mpas-system/mpas-test-project"}, applier.changed)
+	assert.Empty(t, applier.recreated)
+
+	// The controller itself changes a child whose desired state changed.
+	applier = controller.newChildApplier(project)
+	require.NoError(t, applier.observe(context.Background(), gitRepo, u, ssa.ConfiguredAction, false))
+	assert.Empty(t, applier.changed)
+
+	// A child that is gone right after it was applied fails the reconciliation instead of being recorded
+	// without a UID.
+	require.NoError(t, client.Delete(context.Background(), gitRepo))
+	assert.True(t, apierrors.IsNotFound(applier.observe(context.Background(), gitRepo, u, ssa.ConfiguredAction, false)))
+}
+
+func TestProjectObserveAPIReader(t *testing.T) {
+	project := DefaultProject.DeepCopy()
+	cr := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mpas-projects-clusterrole",
+		},
+	}
+
+	controllerutil.AddFinalizer(project, mpasv1alpha1.ProjectFinalizer)
+
+	client := env.FakeKubeClient(WithAddToScheme(mpasv1alpha1.AddToScheme), WithObjects(project, cr))
+	reader := &countingReader{Reader: client}
+	controller := &ProjectReconciler{
+		Client:           client,
+		APIReader:        reader,
+		EventRecorder:    &mockEventRecorder{},
+		Scheme:           env.scheme,
+		ClusterRoleName:  cr.Name,
+		Prefix:           "mpas",
+		DefaultNamespace: "mpas-system",
+	}
+
+	applier := controller.newChildApplier(project)
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "applied",
+		},
+	}
+	require.NoError(t, applier.apply(context.Background(), ns))
+
+	// The live state of the applied child is read from the API server, not from the cache.
+	assert.Equal(t, 1, reader.gets)
+	assert.NotEmpty(t, ns.UID)
+}
+
+type countingReader struct {
+	ctrlclient.Reader
+	gets  int
+	lists int
+	// listErr is returned by List if set.
+	listErr error
+}
+
+func (r *countingReader) Get(ctx context.Context, key ctrlclient.ObjectKey, obj ctrlclient.Object, opts ...ctrlclient.GetOption) error {
+	r.gets++
+
+	return r.Reader.Get(ctx, key, obj, opts...)
+}
+
+func (r *countingReader) List(ctx context.Context, list ctrlclient.ObjectList, opts ...ctrlclient.ListOption) error {
+	r.lists++
+	if r.listErr != nil {
+		return r.listErr
+	}
+
+	return r.Reader.List(ctx, list, opts...)
+}
+
+func TestProjectInventoryStorage(t *testing.T) {
+	project := DefaultProject.DeepCopy()
+	cr := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mpas-projects-clusterrole",
+		},
+	}
+
+	controllerutil.AddFinalizer(project, mpasv1alpha1.ProjectFinalizer)
+
+	client := env.FakeKubeClient(WithAddToScheme(mpasv1alpha1.AddToScheme), WithObjects(project, cr))
+	controller := &ProjectReconciler{
+		Client:             client,
+		EventRecorder:      &mockEventRecorder{},
+		Scheme:             env.scheme,
+		ClusterRoleName:    cr.Name,
+		Prefix:             "mpas",
+		DefaultNamespace:   "mpas-system",
+		InventoryThreshold: 1,
+	}
+
+	key := types.NamespacedName{
+		Namespace: project.Namespace,
+		Name:      project.Name,
+	}
+
+	_, err := controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	// The inventory is moved to a ConfigMap that is owned by the project.
+	require.NoError(t, client.Get(context.Background(), key, project))
+	require.NotNil(t, project.Status.Inventory.ConfigMapRef)
+	assert.Empty(t, project.Status.Inventory.Entries)
+	configMapKey := types.NamespacedName{
+		Namespace: project.Namespace,
+		Name:      project.Status.Inventory.ConfigMapRef.Name,
+	}
+
+	configMap := &corev1.ConfigMap{}
+	require.NoError(t, client.Get(context.Background(), configMapKey, configMap))
+	require.Len(t, configMap.OwnerReferences, 1)
+	assert.Equal(t, project.Name, configMap.OwnerReferences[0].Name)
+
+	inv, err := controller.inventoryStorage().Load(context.Background(), project)
+	require.NoError(t, err)
+	assert.Len(t, inv.Entries, project.Status.Inventory.ConfigMapRef.Count)
+	_, ok := inventory.Entries(inv)["_mpas-test-project__Namespace"]
+	assert.True(t, ok)
+
+	// A lost ConfigMap doesn't block the reconciliation, the inventory is rebuilt from the children.
+	require.NoError(t, client.Delete(context.Background(), configMap))
+	_, err = controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	require.NoError(t, client.Get(context.Background(), key, project))
+	require.NotNil(t, project.Status.Inventory.ConfigMapRef)
+	rebuilt, err := controller.inventoryStorage().Load(context.Background(), project)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, inv.Entries, rebuilt.Entries)
+
+	// The inventory is moved back to the status once it is below the threshold.
+	controller.InventoryThreshold = 0
+	_, err = controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	require.NoError(t, client.Get(context.Background(), key, project))
+	assert.Nil(t, project.Status.Inventory.ConfigMapRef)
+	assert.Equal(t, inv.Entries, project.Status.Inventory.Entries)
+	assert.True(t, apierrors.IsNotFound(client.Get(context.Background(), configMapKey, configMap)))
+}
+
+func TestProjectInventoryVersionMigration(t *testing.T) {
+	project := DefaultProject.DeepCopy()
+	project.Status.Inventory = &mpasv1alpha1.ResourceInventory{
+		Entries: []mpasv1alpha1.ResourceRef{
+			{ID: "mpas-system_stale_kustomize.toolkit.fluxcd.io_Kustomization", Version: "v1beta2"},
+		},
+	}
+	cr := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mpas-projects-clusterrole",
+		},
+	}
+	stale := &kustomizev1.Kustomization{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stale",
+			Namespace: "mpas-system",
+		},
+	}
+
+	// The cluster only serves Kustomizations in v1.
+	restMapper := apimeta.NewDefaultRESTMapper([]schema.GroupVersion{kustomizev1.GroupVersion})
+	restMapper.Add(kustomizev1.GroupVersion.WithKind(kustomizev1.KustomizationKind), apimeta.RESTScopeNamespace)
+
+	controllerutil.AddFinalizer(project, mpasv1alpha1.ProjectFinalizer)
+
+	client := env.FakeKubeClient(
+		WithAddToScheme(mpasv1alpha1.AddToScheme),
+		WithObjects(project, cr, stale),
+		WithRESTMapper(restMapper),
+	)
+	recorder := &mockEventRecorder{}
+	controller := &ProjectReconciler{
+		Client:           client,
+		EventRecorder:    recorder,
+		Scheme:           env.scheme,
+		ClusterRoleName:  cr.Name,
+		Prefix:           "mpas",
+		DefaultNamespace: "mpas-system",
+	}
+
+	key := types.NamespacedName{
+		Namespace: project.Namespace,
+		Name:      project.Name,
+	}
+
+	_, err := controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	assert.Contains(t, recorder.reasons(), mpasv1alpha1.InventoryMigratedReason)
+	assert.True(t, apierrors.IsNotFound(client.Get(context.Background(), types.NamespacedName{Namespace: "mpas-system", Name: "stale"}, stale)))
+
+	require.NoError(t, client.Get(context.Background(), key, project))
+	for _, entry := range project.Status.Inventory.Entries {
+		assert.NotEqual(t, "v1beta2", entry.Version, entry.ID)
+	}
+}
+
+func TestProjectInventoryVersionMigrationFailure(t *testing.T) {
+	project := DefaultProject.DeepCopy()
+	project.Status.Inventory = &mpasv1alpha1.ResourceInventory{
+		Entries: []mpasv1alpha1.ResourceRef{
+			{ID: "mpas-system_stale_kustomize.toolkit.fluxcd.io_Kustomization", Version: "v1beta2"},
+		},
+	}
+	cr := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mpas-projects-clusterrole",
+		},
+	}
+
+	restMapper := apimeta.NewDefaultRESTMapper([]schema.GroupVersion{kustomizev1.GroupVersion})
+	restMapper.Add(kustomizev1.GroupVersion.WithKind(kustomizev1.KustomizationKind), apimeta.RESTScopeNamespace)
+
+	controllerutil.AddFinalizer(project, mpasv1alpha1.ProjectFinalizer)
+
+	client := env.FakeKubeClient(
+		WithAddToScheme(mpasv1alpha1.AddToScheme),
+		WithObjects(project, cr),
+		WithRESTMapper(&failingRESTMapper{RESTMapper: restMapper, group: kustomizev1.GroupVersion.Group}),
+	)
+	controller := &ProjectReconciler{
+		Client:           client,
+		EventRecorder:    &mockEventRecorder{},
+		Scheme:           env.scheme,
+		ClusterRoleName:  cr.Name,
+		Prefix:           "mpas",
+		DefaultNamespace: "mpas-system",
+	}
+
+	key := types.NamespacedName{
+		Namespace: project.Namespace,
+		Name:      project.Name,
+	}
+
+	_, err := controller.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	assert.ErrorContains(t, err, "discovery failed")
+
+	// The failure is reported in the status.
+	require.NoError(t, client.Get(context.Background(), key, project))
+	assert.True(t, conditions.IsStalled(project))
+	assert.True(t, conditions.IsFalse(project, meta.ReadyCondition))
+	assert.Equal(t, mpasv1alpha1.InventoryMigrationFailedReason, conditions.GetReason(project, meta.ReadyCondition))
+}
+
+// failingRESTMapper fails to map the kinds of an API group, as if discovery failed.
+type failingRESTMapper struct {
+	apimeta.RESTMapper
+	group string
+}
+
+func (m *failingRESTMapper) RESTMapping(gk schema.GroupKind, versions ...string) (*apimeta.RESTMapping, error) {
+	if gk.Group == m.group {
+		return nil, errors.New("discovery failed")
+	}
+
+	return m.RESTMapper.RESTMapping(gk, versions...)
+}
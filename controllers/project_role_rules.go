@@ -0,0 +1,148 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Open Component Model contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	mpasv1alpha1 "github.com/open-component-model/mpas-project-controller/api/v1alpha1"
+)
+
+// crdGroupVersionKind is used to watch CustomResourceDefinitions without depending on their API types.
+var crdGroupVersionKind = schema.GroupVersionKind{
+	Group:   "apiextensions.k8s.io",
+	Version: "v1",
+	Kind:    "CustomResourceDefinition",
+}
+
+// projectPolicyRules returns the rules of the project Role. If a discovery client is configured, the rules
+// cover the namespaced resources of the configured API groups that are served by the cluster. Otherwise,
+// a static set of rules is returned.
+func (r *ProjectReconciler) projectPolicyRules(ctx context.Context) ([]rbacv1.PolicyRule, error) {
+	if r.DiscoveryClient == nil {
+		return defaultProjectPolicyRules(), nil
+	}
+
+	_, resourceLists, err := r.DiscoveryClient.ServerGroupsAndResources()
+	if err != nil {
+		if !discovery.IsGroupDiscoveryFailedError(err) {
+			return nil, fmt.Errorf("failed to discover server resources: %w", err)
+		}
+
+		log.FromContext(ctx).Info("failed to discover some API groups, continuing with partial discovery", "error", err.Error())
+	}
+
+	// Collect the resources per group. A resource served in multiple versions is only added once.
+	resources := make(map[string]map[string]struct{})
+	for _, list := range resourceLists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse group version %s: %w", list.GroupVersion, err)
+		}
+
+		for _, resource := range list.APIResources {
+			if !r.grantProjectResource(gv.Group, resource) {
+				continue
+			}
+
+			if resources[gv.Group] == nil {
+				resources[gv.Group] = make(map[string]struct{})
+			}
+
+			resources[gv.Group][resource.Name] = struct{}{}
+		}
+	}
+
+	groups := make([]string, 0, len(resources))
+	for group := range resources {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	rules := []rbacv1.PolicyRule{
+		{
+			APIGroups: []string{""},
+			Resources: []string{"secrets"},
+			Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+		},
+	}
+
+	for _, group := range groups {
+		names := make([]string, 0, len(resources[group]))
+		for name := range resources[group] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: []string{group},
+			Resources: names,
+			Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+		})
+	}
+
+	return rules, nil
+}
+
+// grantProjectResource returns true if the resource is selected by ProjectRoleResources. Entries select a
+// single resource of a group, e.g. kustomize.toolkit.fluxcd.io/kustomizations, or all namespaced resources
+// of a group, e.g. mpas.ocm.software/*. Granting a whole group also grants every resource that is installed
+// in it later on, so it is only done if an operator configures it.
+func (r *ProjectReconciler) grantProjectResource(group string, resource metav1.APIResource) bool {
+	// Skip subresources, cluster scoped resources and the Project itself.
+	if !resource.Namespaced || strings.Contains(resource.Name, "/") {
+		return false
+	}
+
+	if group == mpasv1alpha1.GroupVersion.Group && resource.Name == "projects" {
+		return false
+	}
+
+	return r.projectRoleResource(group, resource.Name)
+}
+
+// projectRoleResource returns true if the resource of the API group is selected by ProjectRoleResources.
+func (r *ProjectReconciler) projectRoleResource(group, resource string) bool {
+	for _, entry := range r.ProjectRoleResources {
+		entryGroup, entryResource, _ := strings.Cut(entry, "/")
+		if entryGroup != group {
+			continue
+		}
+
+		if entryResource == "*" || entryResource == resource {
+			return true
+		}
+	}
+
+	return false
+}
+
+// requestsForCRDChange invalidates the discovery cache and requeues all Projects when a CRD of one of the
+// configured resources is added, established or removed, so that the project Roles follow the installed
+// API versions.
+func (r *ProjectReconciler) requestsForCRDChange(obj client.Object) []reconcile.Request {
+	// CRDs are named <plural>.<group>.
+	plural, group, _ := strings.Cut(obj.GetName(), ".")
+	if !r.projectRoleResource(group, plural) {
+		return nil
+	}
+
+	if cached, ok := r.DiscoveryClient.(discovery.CachedDiscoveryInterface); ok {
+		cached.Invalidate()
+	}
+
+	return r.requestsForAllProjects("crd", obj.GetName())
+}
@@ -0,0 +1,37 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Open Component Model contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	mpasv1alpha1 "github.com/open-component-model/mpas-project-controller/api/v1alpha1"
+)
+
+// pruneDisabled returns true if the object is annotated to be kept when it is pruned or its Project is deleted.
+func pruneDisabled(object *unstructured.Unstructured) bool {
+	return object.GetAnnotations()[mpasv1alpha1.PruneAnnotationKey] == mpasv1alpha1.PruneDisabledValue
+}
+
+// recordPruneSkipped reports the objects that were kept because pruning is disabled for them in the status
+// of the Project, and in an event when they differ from the previously reported objects. Teardown is retried
+// until all objects are gone, so the same objects are skipped repeatedly.
+func (r *ProjectReconciler) recordPruneSkipped(obj *mpasv1alpha1.Project, skipped []string) {
+	slices.Sort(skipped)
+
+	changed := !slices.Equal(obj.Status.PruneSkipped, skipped)
+	obj.Status.PruneSkipped = skipped
+
+	if len(skipped) == 0 || !changed {
+		return
+	}
+
+	r.Eventf(obj, corev1.EventTypeNormal, mpasv1alpha1.PruneSkippedReason,
+		"pruning is disabled for %s", strings.Join(skipped, ", "))
+}
@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Open Component Model contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+var errRBACRejected = errors.New("rbac rule is not permitted")
+
+// validateAdditionalRules verifies that the additional rules of a Project only refer to the API groups,
+// resources and verbs that are permitted by the allow-list of the controller. Wildcard resources are
+// never permitted. Resource names only narrow a rule and are therefore not restricted.
+func (r *ProjectReconciler) validateAdditionalRules(rules []rbacv1.PolicyRule) error {
+	var errs error
+	for i, rule := range rules {
+		if len(rule.NonResourceURLs) > 0 {
+			errs = errors.Join(errs, fmt.Errorf("%w: rule %d: non-resource URLs cannot be granted", errRBACRejected, i))
+		}
+
+		for _, group := range rule.APIGroups {
+			if !allowed(r.AllowedRBACAPIGroups, group) {
+				errs = errors.Join(errs, fmt.Errorf("%w: rule %d: api group %q", errRBACRejected, i, group))
+			}
+		}
+
+		for _, resource := range rule.Resources {
+			if resource == rbacv1.ResourceAll || strings.HasPrefix(resource, rbacv1.ResourceAll+"/") {
+				errs = errors.Join(errs, fmt.Errorf("%w: rule %d: wildcard resources cannot be granted", errRBACRejected, i))
+
+				continue
+			}
+
+			if !allowed(r.AllowedRBACResources, resource) {
+				errs = errors.Join(errs, fmt.Errorf("%w: rule %d: resource %q", errRBACRejected, i, resource))
+			}
+		}
+
+		for _, verb := range rule.Verbs {
+			if !allowed(r.AllowedRBACVerbs, verb) {
+				errs = errors.Join(errs, fmt.Errorf("%w: rule %d: verb %q", errRBACRejected, i, verb))
+			}
+		}
+	}
+
+	return errs
+}
+
+// allowed returns true if the value is contained in the allow-list or the allow-list contains a wildcard.
+func allowed(allowList []string, value string) bool {
+	for _, v := range allowList {
+		if v == rbacv1.APIGroupAll || v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,75 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Open Component Model contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mpasv1alpha1 "github.com/open-component-model/mpas-project-controller/api/v1alpha1"
+)
+
+// resourcesInUse returns the resources of the DeletionBlockingKinds that exist in the project namespace in
+// the format <kind>/<name>. It only looks for them if the Project opted in, is about to delete its namespace
+// and its deletion isn't forced.
+func (r *ProjectReconciler) resourcesInUse(ctx context.Context, obj *mpasv1alpha1.Project) ([]string, error) {
+	if obj.Spec.DeletionPolicy == nil || !obj.Spec.DeletionPolicy.BlockWhileInUse {
+		return nil, nil
+	}
+
+	if obj.GetDeletionPolicy("Namespace") != mpasv1alpha1.DeletionPolicyDelete {
+		return nil, nil
+	}
+
+	if obj.GetAnnotations()[mpasv1alpha1.ForceDeleteAnnotationKey] == "true" {
+		return nil, nil
+	}
+
+	namespace := obj.GetNameWithPrefix(r.Prefix)
+
+	var inUse []string
+	for _, entry := range r.DeletionBlockingKinds {
+		group, kind, ok := strings.Cut(entry, "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid deletion blocking kind %s, expected <group>/<kind>", entry)
+		}
+
+		mapping, err := r.RESTMapper().RESTMapping(schema.GroupKind{Group: group, Kind: kind})
+		if err != nil {
+			// Kinds that aren't installed in the cluster can't block the deletion.
+			if apimeta.IsNoMatchError(err) {
+				continue
+			}
+
+			return nil, fmt.Errorf("failed to get rest mapping of %s: %w", entry, err)
+		}
+
+		// The kinds are configured by the operator, so they are listed from the API server instead of
+		// starting a cluster-wide informer for each of them. A missing permission fails the deletion with an
+		// error instead of a cache that never syncs.
+		list := &metav1.PartialObjectMetadataList{}
+		list.SetGroupVersionKind(mapping.GroupVersionKind.GroupVersion().WithKind(kind + "List"))
+		if err := r.apiReader().List(ctx, list, client.InNamespace(namespace)); err != nil {
+			if apierrors.IsNotFound(err) || apimeta.IsNoMatchError(err) {
+				continue
+			}
+
+			return nil, fmt.Errorf("failed to list %s in namespace %s: %w", entry, namespace, err)
+		}
+
+		for _, item := range list.Items {
+			inUse = append(inUse, fmt.Sprintf("%s/%s", kind, item.Name))
+		}
+	}
+
+	return inUse, nil
+}
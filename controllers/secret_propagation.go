@@ -0,0 +1,228 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Open Component Model contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/open-component-model/mpas-project-controller/api/v1alpha1"
+	"github.com/open-component-model/mpas-project-controller/inventory"
+)
+
+// propagatedSecretAnnotationValue is the value of the managed secret annotation of propagated Secrets.
+const propagatedSecretAnnotationValue = "propagated"
+
+var errPropagationConflict = errors.New("secret exists and was not propagated")
+
+// reconcilePropagation copies the given Secret of the controller namespace into the namespaces of the Projects
+// that match its propagation annotation and removes the copies from all other project namespaces. The copies
+// are annotated as managed secrets, so that they are added to the project ServiceAccount.
+func (r *SecretsReconciler) reconcilePropagation(ctx context.Context, key types.NamespacedName) error {
+	logger := log.FromContext(ctx)
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, key, secret); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to fetch secret from cluster: %w", err)
+		}
+
+		secret = nil
+	}
+
+	selector := labels.Nothing()
+	if secret != nil && secret.DeletionTimestamp == nil {
+		var err error
+		selector, err = propagationSelector(secret)
+		if err != nil {
+			logger.Error(err, "invalid propagation annotation")
+			r.EventRecorder.Event(secret, corev1.EventTypeWarning, v1alpha1.SecretPropagationFailedReason, err.Error())
+
+			return nil
+		}
+	}
+
+	projects := &v1alpha1.ProjectList{}
+	if err := r.List(ctx, projects, client.InNamespace(r.DefaultNamespace)); err != nil {
+		return fmt.Errorf("failed to list projects: %w", err)
+	}
+
+	var retErr error
+	for i := range projects.Items {
+		project := &projects.Items[i]
+		if !project.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		inv, err := inventory.Storage{Client: r.Client}.Load(ctx, project)
+		if err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("failed to load inventory of project %s: %w", project.Name, err))
+
+			continue
+		}
+
+		namespace, err := inventory.Namespace(inv)
+		if err != nil {
+			// The project namespace doesn't exist yet.
+			continue
+		}
+
+		if !selector.Matches(labels.Set(project.Labels)) {
+			if err := r.removePropagatedSecret(ctx, key, namespace); err != nil {
+				retErr = errors.Join(retErr, err)
+			}
+
+			continue
+		}
+
+		if err := r.propagateSecret(ctx, secret, namespace); err != nil {
+			if errors.Is(err, errPropagationConflict) {
+				r.EventRecorder.Eventf(secret, corev1.EventTypeWarning, v1alpha1.SecretPropagationFailedReason,
+					"failed to propagate secret to namespace %s: %s", namespace, err)
+
+				continue
+			}
+
+			retErr = errors.Join(retErr, err)
+		}
+	}
+
+	return retErr
+}
+
+// propagationSelector returns the selector for the Projects the Secret is propagated to.
+func propagationSelector(secret *corev1.Secret) (labels.Selector, error) {
+	value, ok := secret.Annotations[v1alpha1.PropagateSecretAnnotationKey]
+	if !ok {
+		return labels.Nothing(), nil
+	}
+
+	if value == v1alpha1.PropagateToAllProjects {
+		return labels.Everything(), nil
+	}
+
+	if value == "" {
+		return nil, fmt.Errorf("annotation %s must be %q or a label selector", v1alpha1.PropagateSecretAnnotationKey, v1alpha1.PropagateToAllProjects)
+	}
+
+	selector, err := labels.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse project selector of annotation %s: %w", v1alpha1.PropagateSecretAnnotationKey, err)
+	}
+
+	return selector, nil
+}
+
+// propagateSecret creates or updates the copy of the Secret in the given namespace. Secrets in the namespace
+// that were not propagated from it are left alone.
+func (r *SecretsReconciler) propagateSecret(ctx context.Context, secret *corev1.Secret, namespace string) error {
+	source := client.ObjectKeyFromObject(secret).String()
+	propagated := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secret.Name,
+			Namespace: namespace,
+		},
+	}
+
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, propagated, func() error {
+		if propagated.ResourceVersion != "" && propagated.Annotations[v1alpha1.PropagatedFromAnnotationKey] != source {
+			return fmt.Errorf("%w: %s", errPropagationConflict, client.ObjectKeyFromObject(propagated))
+		}
+
+		if propagated.Annotations == nil {
+			propagated.Annotations = make(map[string]string)
+		}
+
+		propagated.Annotations[v1alpha1.PropagatedFromAnnotationKey] = source
+		propagated.Annotations[v1alpha1.ManagedMPASSecretAnnotationKey] = propagatedSecretAnnotationValue
+		propagated.Type = secret.Type
+		propagated.Data = secret.Data
+
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, errPropagationConflict) {
+			return err
+		}
+
+		return fmt.Errorf("failed to propagate secret to namespace %s: %w", namespace, err)
+	}
+
+	return nil
+}
+
+// removePropagatedSecret deletes the copy of the Secret with the given key from the namespace if it exists.
+func (r *SecretsReconciler) removePropagatedSecret(ctx context.Context, key types.NamespacedName, namespace string) error {
+	propagated := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: key.Name, Namespace: namespace}, propagated); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to fetch propagated secret: %w", err)
+	}
+
+	if propagated.Annotations[v1alpha1.PropagatedFromAnnotationKey] != key.String() {
+		return nil
+	}
+
+	if err := r.Delete(ctx, propagated); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to delete propagated secret from namespace %s: %w", namespace, err)
+	}
+
+	return nil
+}
+
+// requestsForPropagatedSecret returns the request for the Secret a propagated Secret is copied from, so that
+// changes to the copy are reverted.
+func (r *SecretsReconciler) requestsForPropagatedSecret(obj client.Object) []reconcile.Request {
+	source, ok := obj.GetAnnotations()[v1alpha1.PropagatedFromAnnotationKey]
+	if !ok {
+		return nil
+	}
+
+	namespace, name, ok := strings.Cut(source, string(types.Separator))
+	if !ok || namespace != r.DefaultNamespace {
+		return nil
+	}
+
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}}}
+}
+
+// requestsForProjectSecrets returns the requests for all propagated Secrets, so that they are copied into
+// new project namespaces and removed from namespaces of Projects that no longer match.
+func (r *SecretsReconciler) requestsForProjectSecrets(_ client.Object) []reconcile.Request {
+	ctx := context.Background()
+	logger := log.FromContext(ctx)
+
+	secrets := &corev1.SecretList{}
+	if err := r.List(ctx, secrets, client.InNamespace(r.DefaultNamespace)); err != nil {
+		logger.Error(err, "failed to list secrets")
+
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range secrets.Items {
+		if _, ok := secrets.Items[i].Annotations[v1alpha1.PropagateSecretAnnotationKey]; ok {
+			requests = append(requests, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&secrets.Items[i])})
+		}
+	}
+
+	return requests
+}
@@ -0,0 +1,106 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Open Component Model contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/open-component-model/mpas-project-controller/api/v1alpha1"
+	"github.com/open-component-model/mpas-project-controller/inventory"
+)
+
+// SecretPropagationPredicate triggers on any change of Secrets that are propagated into project namespaces
+// or are copies of such Secrets, including changes of their data.
+type SecretPropagationPredicate struct {
+	predicate.Funcs
+}
+
+// Update triggers if the old or the new Secret takes part in the propagation.
+func (SecretPropagationPredicate) Update(e event.UpdateEvent) bool {
+	return isPropagationSecret(e.ObjectOld) || isPropagationSecret(e.ObjectNew)
+}
+
+// Create triggers if the Secret takes part in the propagation.
+func (SecretPropagationPredicate) Create(e event.CreateEvent) bool {
+	return isPropagationSecret(e.Object)
+}
+
+// Delete triggers if the Secret takes part in the propagation.
+func (SecretPropagationPredicate) Delete(e event.DeleteEvent) bool {
+	return isPropagationSecret(e.Object)
+}
+
+func isPropagationSecret(obj client.Object) bool {
+	if obj == nil {
+		return false
+	}
+
+	annotations := obj.GetAnnotations()
+	_, propagate := annotations[v1alpha1.PropagateSecretAnnotationKey]
+	_, propagated := annotations[v1alpha1.PropagatedFromAnnotationKey]
+
+	return propagate || propagated
+}
+
+// ProjectPropagationPredicate triggers on the changes of Projects that affect the propagation of Secrets:
+// changes of their labels, which select the propagated Secrets, the appearance of the project namespace in
+// their inventory and their deletion. Created Projects don't have a project namespace yet.
+type ProjectPropagationPredicate struct {
+	predicate.Funcs
+}
+
+// Update triggers if the labels changed or the project namespace appeared in the inventory.
+func (ProjectPropagationPredicate) Update(e event.UpdateEvent) bool {
+	oldProject, ok := e.ObjectOld.(*v1alpha1.Project)
+	if !ok {
+		return false
+	}
+
+	newProject, ok := e.ObjectNew.(*v1alpha1.Project)
+	if !ok {
+		return false
+	}
+
+	if !equality.Semantic.DeepEqual(oldProject.GetLabels(), newProject.GetLabels()) {
+		return true
+	}
+
+	return !hasInventoryNamespace(oldProject) && hasInventoryNamespace(newProject)
+}
+
+// Create doesn't trigger, since the project namespace isn't known yet.
+func (ProjectPropagationPredicate) Create(event.CreateEvent) bool {
+	return false
+}
+
+// Delete triggers for every Project.
+func (ProjectPropagationPredicate) Delete(event.DeleteEvent) bool {
+	return true
+}
+
+// Generic doesn't trigger.
+func (ProjectPropagationPredicate) Generic(event.GenericEvent) bool {
+	return false
+}
+
+// hasInventoryNamespace returns true if the project namespace is recorded in the inventory of the Project.
+// Inventories stored in a ConfigMap always contain it.
+func hasInventoryNamespace(project *v1alpha1.Project) bool {
+	inv := project.Status.Inventory
+	if inv == nil {
+		return false
+	}
+
+	if inv.ConfigMapRef != nil {
+		return true
+	}
+
+	_, err := inventory.Namespace(inv)
+
+	return err == nil
+}
@@ -15,10 +15,13 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/open-component-model/mpas-project-controller/api/v1alpha1"
+	"github.com/open-component-model/mpas-project-controller/inventory"
 )
 
 // SecretsReconciler reconciles a Secret object.
@@ -33,7 +36,21 @@ type SecretsReconciler struct {
 // SetupWithManager sets up the controller with the Manager.
 func (r *SecretsReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&corev1.Secret{}, builder.WithPredicates(predicate.GenerationChangedPredicate{}, &SecretAnnotationExistsPredicate{})).
+		For(&corev1.Secret{}, builder.WithPredicates(
+			predicate.Or(
+				predicate.And(predicate.GenerationChangedPredicate{}, &SecretAnnotationExistsPredicate{}),
+				SecretPropagationPredicate{},
+			),
+		)).
+		Watches(
+			&source.Kind{Type: &corev1.Secret{}},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForPropagatedSecret),
+		).
+		Watches(
+			&source.Kind{Type: &v1alpha1.Project{}},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForProjectSecrets),
+			builder.WithPredicates(ProjectPropagationPredicate{}),
+		).
 		Complete(r)
 }
 
@@ -43,6 +60,11 @@ func (r *SecretsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_
 	logger := log.FromContext(ctx)
 	logger.Info("reconciling object", "secret", req.NamespacedName)
 
+	// Secrets of the controller namespace are propagated into project namespaces.
+	if req.Namespace == r.DefaultNamespace {
+		return ctrl.Result{}, r.reconcilePropagation(ctx, req.NamespacedName)
+	}
+
 	secret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      req.Name,
@@ -67,8 +89,13 @@ func (r *SecretsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_
 		return ctrl.Result{RequeueAfter: project.GetRequeueAfter()}, nil
 	}
 
+	inv, err := inventory.Storage{Client: r.Client}.Load(ctx, project)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to load project inventory: %w", err)
+	}
+
 	serviceAccount := &corev1.ServiceAccount{}
-	key, err := project.GetServiceAccountNamespacedName()
+	key, err := inventory.ServiceAccount(inv)
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("failed to find project service account in inventory: %w", err)
 	}
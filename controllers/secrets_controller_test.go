@@ -3,6 +3,7 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -18,6 +20,7 @@ import (
 	controllerruntime "sigs.k8s.io/controller-runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 
 	"github.com/open-component-model/mpas-project-controller/api/v1alpha1"
 )
@@ -65,7 +68,7 @@ func TestSecretsReconciler_Reconcile(t *testing.T) {
 						Inventory: &v1alpha1.ResourceInventory{
 							Entries: []v1alpha1.ResourceRef{
 								{
-									ID:      "test-namespace_test-service-account_v1_ServiceAccount",
+									ID:      "test-namespace_test-service-account__ServiceAccount",
 									Version: "1",
 								},
 							},
@@ -139,7 +142,7 @@ func TestSecretsReconciler_Reconcile(t *testing.T) {
 						Inventory: &v1alpha1.ResourceInventory{
 							Entries: []v1alpha1.ResourceRef{
 								{
-									ID:      "test-namespace_test-service-account_v1_ServiceAccount",
+									ID:      "test-namespace_test-service-account__ServiceAccount",
 									Version: "1",
 								},
 							},
@@ -214,7 +217,7 @@ func TestSecretsReconciler_Reconcile(t *testing.T) {
 						Inventory: &v1alpha1.ResourceInventory{
 							Entries: []v1alpha1.ResourceRef{
 								{
-									ID:      "test-namespace_test-service-account_v1_ServiceAccount",
+									ID:      "test-namespace_test-service-account__ServiceAccount",
 									Version: "1",
 								},
 							},
@@ -286,7 +289,7 @@ func TestSecretsReconciler_Reconcile(t *testing.T) {
 						Inventory: &v1alpha1.ResourceInventory{
 							Entries: []v1alpha1.ResourceRef{
 								{
-									ID:      "test-namespace_test-service-account_v1_ServiceAccount",
+									ID:      "test-namespace_test-service-account__ServiceAccount",
 									Version: "1",
 								},
 							},
@@ -355,7 +358,7 @@ func TestSecretsReconciler_Reconcile(t *testing.T) {
 						Inventory: &v1alpha1.ResourceInventory{
 							Entries: []v1alpha1.ResourceRef{
 								{
-									ID:      "test-namespace_test-service-account_v1_ServiceAccount",
+									ID:      "test-namespace_test-service-account__ServiceAccount",
 									Version: "1",
 								},
 							},
@@ -428,15 +431,166 @@ func TestSecretsReconciler_Reconcile(t *testing.T) {
 	}
 }
 
+func TestSecretsReconciler_Propagation(t *testing.T) {
+	projectWithNamespace := func(name, team string) *v1alpha1.Project {
+		return &v1alpha1.Project{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "mpas-system",
+				Labels:    map[string]string{"team": team},
+			},
+			Status: v1alpha1.ProjectStatus{
+				Inventory: &v1alpha1.ResourceInventory{
+					Entries: []v1alpha1.ResourceRef{
+						{
+							ID:      "_mpas-" + name + "__Namespace",
+							Version: "v1",
+						},
+					},
+				},
+			},
+		}
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "registry-credentials",
+			Namespace: "mpas-system",
+			Annotations: map[string]string{
+				v1alpha1.PropagateSecretAnnotationKey: "team=a",
+			},
+		},
+		Data: map[string][]byte{
+			".dockerconfigjson": []byte("{}"),
+		},
+		Type: corev1.SecretTypeDockerConfigJson,
+	}
+	// A secret of the team that has the same name must not be overwritten.
+	teamSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "registry-credentials",
+			Namespace: "mpas-project-c",
+		},
+	}
+
+	c := env.FakeKubeClient(
+		WithAddToScheme(v1alpha1.AddToScheme),
+		WithObjects(
+			projectWithNamespace("project-a", "a"),
+			projectWithNamespace("project-b", "b"),
+			projectWithNamespace("project-c", "c"),
+			secret,
+			teamSecret,
+		),
+	)
+	recorder := &mockEventRecorder{}
+	r := &SecretsReconciler{
+		Client:           c,
+		Scheme:           env.scheme,
+		DefaultNamespace: "mpas-system",
+		EventRecorder:    recorder,
+	}
+
+	key := types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}
+	reconcile := func() {
+		_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+		require.NoError(t, err)
+	}
+	propagated := func(namespace string) *corev1.Secret {
+		s := &corev1.Secret{}
+		if err := c.Get(context.Background(), types.NamespacedName{Name: secret.Name, Namespace: namespace}, s); err != nil {
+			require.True(t, apierrors.IsNotFound(err))
+
+			return nil
+		}
+
+		return s
+	}
+
+	reconcile()
+
+	copied := propagated("mpas-project-a")
+	require.NotNil(t, copied)
+	assert.Equal(t, secret.Data, copied.Data)
+	assert.Equal(t, corev1.SecretTypeDockerConfigJson, copied.Type)
+	assert.Equal(t, "mpas-system/registry-credentials", copied.Annotations[v1alpha1.PropagatedFromAnnotationKey])
+	assert.Contains(t, copied.Annotations, v1alpha1.ManagedMPASSecretAnnotationKey)
+	assert.Nil(t, propagated("mpas-project-b"))
+
+	// Changes are kept in sync and the secret is removed from projects that no longer match.
+	require.NoError(t, c.Get(context.Background(), key, secret))
+	secret.Annotations[v1alpha1.PropagateSecretAnnotationKey] = "team in (b, c)"
+	secret.Data[".dockerconfigjson"] = []byte(`{"auths":{}}`)
+	require.NoError(t, c.Update(context.Background(), secret))
+
+	reconcile()
+
+	assert.Nil(t, propagated("mpas-project-a"))
+	copied = propagated("mpas-project-b")
+	require.NotNil(t, copied)
+	assert.Equal(t, secret.Data, copied.Data)
+	assert.NotContains(t, propagated("mpas-project-c").Annotations, v1alpha1.PropagatedFromAnnotationKey)
+	assert.True(t, recorder.called)
+
+	// Deleting the secret removes all copies.
+	require.NoError(t, c.Delete(context.Background(), secret))
+
+	reconcile()
+
+	assert.Nil(t, propagated("mpas-project-b"))
+	assert.NotNil(t, propagated("mpas-project-c"))
+}
+
+func TestProjectPropagationPredicate(t *testing.T) {
+	project := &v1alpha1.Project{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "project-a",
+			Namespace: "mpas-system",
+			Labels:    map[string]string{"team": "a"},
+		},
+	}
+	withNamespace := project.DeepCopy()
+	withNamespace.Status.Inventory = &v1alpha1.ResourceInventory{
+		Entries: []v1alpha1.ResourceRef{{ID: "_mpas-project-a__Namespace", Version: "v1"}},
+	}
+	relabelled := withNamespace.DeepCopy()
+	relabelled.Labels["team"] = "b"
+	reconciled := withNamespace.DeepCopy()
+	reconciled.Status.ObservedGeneration = 2
+	conditions.MarkTrue(reconciled, meta.ReadyCondition, meta.SucceededReason, "Reconciliation success")
+
+	p := ProjectPropagationPredicate{}
+	assert.False(t, p.Create(event.CreateEvent{Object: project}))
+	assert.True(t, p.Update(event.UpdateEvent{ObjectOld: project, ObjectNew: withNamespace}))
+	assert.True(t, p.Update(event.UpdateEvent{ObjectOld: withNamespace, ObjectNew: relabelled}))
+	assert.False(t, p.Update(event.UpdateEvent{ObjectOld: withNamespace, ObjectNew: reconciled}))
+	assert.False(t, p.Update(event.UpdateEvent{ObjectOld: withNamespace, ObjectNew: project}))
+	assert.True(t, p.Delete(event.DeleteEvent{Object: withNamespace}))
+}
+
 type mockEventRecorder struct {
 	called bool
+	// events contains the reasons and messages of the recorded events in the format <reason>: <message>.
+	events []string
 }
 
 func (m *mockEventRecorder) Event(object runtime.Object, eventtype, reason, message string) {
 	m.called = true
+	m.events = append(m.events, reason+": "+message)
 }
 
 func (m *mockEventRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...any) {
+	m.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+// reasons returns the reasons of the recorded events.
+func (m *mockEventRecorder) reasons() []string {
+	var reasons []string
+	for _, e := range m.events {
+		reason, _, _ := strings.Cut(e, ":")
+		reasons = append(reasons, reason)
+	}
+
+	return reasons
 }
 
 func (m *mockEventRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...any) {
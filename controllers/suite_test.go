@@ -5,15 +5,24 @@
 package controllers
 
 import (
+	"context"
+	"encoding/json"
+	"strings"
 	"testing"
 
 	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1"
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/uuid"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
@@ -23,8 +32,9 @@ import (
 )
 
 type testEnv struct {
-	scheme *runtime.Scheme
-	obj    []client.Object
+	scheme     *runtime.Scheme
+	obj        []client.Object
+	restMapper meta.RESTMapper
 }
 
 // FakeKubeClientOption defines options to construct a fake kube client. There are some defaults involved.
@@ -46,12 +56,181 @@ func WithObjects(obj ...client.Object) FakeKubeClientOption {
 	}
 }
 
+// WithRESTMapper sets the RESTMapper of the client. It only applies to the client that is constructed with it.
+func WithRESTMapper(restMapper meta.RESTMapper) FakeKubeClientOption {
+	return func(testEnv *testEnv) {
+		testEnv.restMapper = restMapper
+	}
+}
+
 func (t *testEnv) FakeKubeClient(opts ...FakeKubeClientOption) client.Client {
+	t.restMapper = nil
 	for _, opt := range opts {
 		opt(t)
 	}
 
-	return fake.NewClientBuilder().WithScheme(t.scheme).WithObjects(t.obj...).Build()
+	builder := fake.NewClientBuilder().WithScheme(t.scheme).WithObjects(t.obj...)
+	if t.restMapper != nil {
+		builder = builder.WithRESTMapper(t.restMapper)
+	}
+
+	return &applyClient{
+		Client: builder.Build(),
+	}
+}
+
+// applyClient emulates server-side apply on top of the fake client, which doesn't support apply patches.
+// The top-level fields of the applied object replace the existing ones, while the labels and annotations
+// are merged and the fields maintained by the server are kept. Fields of the spec that are managed by other
+// field managers are kept as well, and the applied fields of the spec are recorded in the managed fields.
+type applyClient struct {
+	client.Client
+}
+
+// Create sets the UID of the object like the API server, which the fake client doesn't.
+func (c *applyClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if obj.GetUID() == "" {
+		obj.SetUID(uuid.NewUUID())
+	}
+
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *applyClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	applied, ok := obj.(*unstructured.Unstructured)
+	if !ok || patch.Type() != types.ApplyPatchType {
+		return c.Client.Patch(ctx, obj, patch, opts...)
+	}
+
+	patchOptions := &client.PatchOptions{}
+	patchOptions.ApplyOptions(opts)
+	dryRun := len(patchOptions.DryRun) > 0
+
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(applied.GroupVersionKind())
+	if err := c.Client.Get(ctx, client.ObjectKeyFromObject(applied), existing); err != nil {
+		if !apierrors.IsNotFound(err) || dryRun {
+			return client.IgnoreNotFound(err)
+		}
+
+		applied.SetManagedFields(applyManagedFields(nil, applied, patchOptions.FieldManager))
+
+		return c.Create(ctx, applied)
+	}
+
+	for key, value := range existing.Object {
+		if _, ok := applied.Object[key]; !ok || key == "status" {
+			applied.Object[key] = value
+		}
+	}
+
+	retainManagedSpecFields(existing, applied, patchOptions.FieldManager)
+	applied.SetManagedFields(applyManagedFields(existing.GetManagedFields(), applied, patchOptions.FieldManager))
+
+	applied.SetLabels(mergeMaps(existing.GetLabels(), applied.GetLabels()))
+	applied.SetAnnotations(mergeMaps(existing.GetAnnotations(), applied.GetAnnotations()))
+	applied.SetResourceVersion(existing.GetResourceVersion())
+	applied.SetUID(existing.GetUID())
+	applied.SetCreationTimestamp(existing.GetCreationTimestamp())
+	applied.SetDeletionTimestamp(existing.GetDeletionTimestamp())
+	applied.SetFinalizers(existing.GetFinalizers())
+	if len(applied.GetOwnerReferences()) == 0 {
+		applied.SetOwnerReferences(existing.GetOwnerReferences())
+	}
+
+	if dryRun {
+		return nil
+	}
+
+	return c.Client.Update(ctx, applied)
+}
+
+// retainManagedSpecFields keeps the fields of the existing spec that aren't applied, but are managed by another
+// field manager than the applying one.
+func retainManagedSpecFields(existing, applied *unstructured.Unstructured, owner string) {
+	existingSpec, ok := existing.Object["spec"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	appliedSpec, ok := applied.Object["spec"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	for _, entry := range existing.GetManagedFields() {
+		if entry.FieldsV1 == nil || (entry.Manager == owner && entry.Operation == metav1.ManagedFieldsOperationApply) {
+			continue
+		}
+
+		fields := map[string]interface{}{}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			continue
+		}
+
+		specFields, _ := fields["f:spec"].(map[string]interface{})
+		for field := range specFields {
+			name := strings.TrimPrefix(field, "f:")
+			if _, ok := appliedSpec[name]; ok {
+				continue
+			}
+
+			if value, ok := existingSpec[name]; ok {
+				appliedSpec[name] = value
+			}
+		}
+	}
+}
+
+// applyManagedFields replaces the managed fields entry of the applying field manager with the applied fields
+// of the spec.
+func applyManagedFields(existing []metav1.ManagedFieldsEntry, applied *unstructured.Unstructured, owner string) []metav1.ManagedFieldsEntry {
+	if owner == "" {
+		return existing
+	}
+
+	result := make([]metav1.ManagedFieldsEntry, 0, len(existing)+1)
+	for _, entry := range existing {
+		if entry.Manager != owner || entry.Operation != metav1.ManagedFieldsOperationApply {
+			result = append(result, entry)
+		}
+	}
+
+	specFields := map[string]interface{}{}
+	appliedSpec, _ := applied.Object["spec"].(map[string]interface{})
+	for field := range appliedSpec {
+		specFields["f:"+field] = map[string]interface{}{}
+	}
+
+	raw, err := json.Marshal(map[string]interface{}{"f:spec": specFields})
+	if err != nil {
+		panic(err)
+	}
+
+	return append(result, metav1.ManagedFieldsEntry{
+		Manager:    owner,
+		Operation:  metav1.ManagedFieldsOperationApply,
+		APIVersion: applied.GetAPIVersion(),
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: raw},
+	})
+}
+
+func mergeMaps(existing, applied map[string]string) map[string]string {
+	if len(existing) == 0 && len(applied) == 0 {
+		return nil
+	}
+
+	result := make(map[string]string, len(existing)+len(applied))
+	for k, v := range existing {
+		result[k] = v
+	}
+
+	for k, v := range applied {
+		result[k] = v
+	}
+
+	return result
 }
 
 var (
@@ -84,6 +263,7 @@ func TestMain(m *testing.M) {
 	scheme := runtime.NewScheme()
 	_ = corev1.AddToScheme(scheme)
 	_ = rbacv1.AddToScheme(scheme)
+	_ = networkingv1.AddToScheme(scheme)
 	_ = sourcev1.AddToScheme(scheme)
 	_ = kustomizev1.AddToScheme(scheme)
 	_ = mpasv1alpha1.AddToScheme(scheme)
@@ -0,0 +1,163 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Open Component Model contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1"
+	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/fluxcd/pkg/runtime/conditions"
+	"github.com/fluxcd/pkg/ssa"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	gcv1alpha1 "github.com/open-component-model/git-controller/apis/mpas/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	mpasv1alpha1 "github.com/open-component-model/mpas-project-controller/api/v1alpha1"
+)
+
+// teardownRequeueInterval is the interval in which a terminating Project checks if its resources are gone.
+const teardownRequeueInterval = 5 * time.Second
+
+// teardownStages contains the names of the stages in which the resources of a deleted Project are deleted.
+// A stage starts once all resources of the previous stage are gone.
+var teardownStages = []string{"Kustomizations", "sources", "resources", "namespace"}
+
+// teardownStage returns the index of the stage in which the given object is deleted. The Kustomizations
+// are deleted before their sources, and the namespace is deleted last, so that nothing is left behind
+// with a finalizer that can't be processed anymore.
+func teardownStage(object *unstructured.Unstructured) int {
+	switch object.GroupVersionKind().GroupKind() {
+	case schema.GroupKind{Group: kustomizev1.GroupVersion.Group, Kind: kustomizev1.KustomizationKind}:
+		return 0
+	case schema.GroupKind{Group: sourcev1.GroupVersion.Group, Kind: sourcev1.GitRepositoryKind},
+		schema.GroupKind{Group: gcv1alpha1.GroupVersion.Group, Kind: "Repository"}:
+		return 1
+	case schema.GroupKind{Kind: "Namespace"}:
+		return 3
+	default:
+		return 2
+	}
+}
+
+// teardown orphans or deletes the given objects of a deleted Project stage by stage. It returns true once
+// all deleted objects are gone and reports the progress in the Terminating condition.
+func (r *ProjectReconciler) teardown(ctx context.Context, obj *mpasv1alpha1.Project, objects []*unstructured.Unstructured) (bool, error) {
+	logger := log.FromContext(ctx)
+	var retErr error
+
+	var skipped []string
+	stages := make([][]*unstructured.Unstructured, len(teardownStages))
+	for _, object := range objects {
+		orphan := obj.GetDeletionPolicy(object.GetKind()) == mpasv1alpha1.DeletionPolicyOrphan
+		if !orphan {
+			if err := r.Client.Get(ctx, client.ObjectKeyFromObject(object), object); client.IgnoreNotFound(err) != nil {
+				logger.Error(err, "failed to get object for deletion", "object", object)
+				retErr = errors.Join(retErr, err)
+
+				continue
+			}
+
+			if pruneDisabled(object) {
+				skipped = append(skipped, ssa.FmtUnstructured(object))
+				orphan = true
+			}
+		}
+
+		if orphan {
+			if err := r.orphan(ctx, obj, object); err != nil {
+				logger.Error(err, "failed to orphan object", "object", object)
+				retErr = errors.Join(retErr, err)
+			}
+
+			continue
+		}
+
+		stage := teardownStage(object)
+		stages[stage] = append(stages[stage], object)
+	}
+
+	r.recordPruneSkipped(obj, skipped)
+
+	if retErr != nil {
+		return false, retErr
+	}
+
+	for i, stage := range stages {
+		var remaining []string
+		for _, object := range stage {
+			gone, err := r.deleteObject(ctx, object)
+			if err != nil {
+				logger.Error(err, "failed to delete object", "object", object)
+				retErr = errors.Join(retErr, err)
+
+				continue
+			}
+
+			if !gone {
+				remaining = append(remaining, fmt.Sprintf("%s/%s", object.GetKind(), object.GetName()))
+			}
+		}
+
+		if retErr != nil {
+			return false, retErr
+		}
+
+		if len(remaining) > 0 {
+			conditions.MarkTrue(obj, mpasv1alpha1.TerminatingCondition, meta.ProgressingReason,
+				"waiting for %s to be deleted: %s", teardownStages[i], strings.Join(remaining, ", "))
+
+			return false, nil
+		}
+	}
+
+	conditions.MarkTrue(obj, mpasv1alpha1.TerminatingCondition, meta.SucceededReason, "all resources are deleted")
+
+	return true, nil
+}
+
+// deleteObject deletes the given object and returns true once it is gone. Kustomizations are deleted without
+// suspending them, because the kustomize-controller only garbage collects the resources of Kustomizations
+// that aren't suspended before it removes its finalizer.
+func (r *ProjectReconciler) deleteObject(ctx context.Context, object *unstructured.Unstructured) (bool, error) {
+	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(object), object); err != nil {
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+
+		return false, fmt.Errorf("failed to get %s %s: %w", object.GetKind(), object.GetName(), err)
+	}
+
+	if object.GetDeletionTimestamp() != nil {
+		return false, nil
+	}
+
+	if err := r.Client.Delete(ctx, object); err != nil {
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+
+		return false, fmt.Errorf("failed to delete %s %s: %w", object.GetKind(), object.GetName(), err)
+	}
+
+	// Objects without finalizers are gone right away.
+	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(object), object); err != nil {
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+
+		return false, fmt.Errorf("failed to get %s %s: %w", object.GetKind(), object.GetName(), err)
+	}
+
+	return false, nil
+}
@@ -8,14 +8,19 @@
 package inventory
 
 import (
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/fluxcd/pkg/ssa"
 	mpasv1alpha1 "github.com/open-component-model/mpas-project-controller/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/cli-utils/pkg/object"
 )
 
@@ -25,7 +30,8 @@ func New() *mpasv1alpha1.ResourceInventory {
 	}
 }
 
-// Add adds a new object reference to the inventory.
+// Add adds a new object reference to the inventory. The UID and the spec checksum of the object are
+// recorded with it.
 func Add(inv *mpasv1alpha1.ResourceInventory, objs ...runtime.Object) error {
 	for _, obj := range objs {
 		objMetadata, err := object.RuntimeToObjMeta(obj)
@@ -33,9 +39,21 @@ func Add(inv *mpasv1alpha1.ResourceInventory, objs ...runtime.Object) error {
 			return fmt.Errorf("could not get object metadata: %w", err)
 		}
 
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return fmt.Errorf("could not access object metadata: %w", err)
+		}
+
+		checksum, err := Checksum(obj)
+		if err != nil {
+			return err
+		}
+
 		entry := mpasv1alpha1.ResourceRef{
-			ID:      objMetadata.String(),
-			Version: obj.GetObjectKind().GroupVersionKind().GroupVersion().Version,
+			ID:       objMetadata.String(),
+			Version:  obj.GetObjectKind().GroupVersionKind().GroupVersion().Version,
+			UID:      accessor.GetUID(),
+			Checksum: checksum,
 		}
 		inv.Entries = append(inv.Entries, entry)
 	}
@@ -43,6 +61,40 @@ func Add(inv *mpasv1alpha1.ResourceInventory, objs ...runtime.Object) error {
 	return nil
 }
 
+// Checksum returns the SHA-256 checksum of the spec of the object. Objects without a spec have no checksum.
+func Checksum(obj runtime.Object) (string, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return "", fmt.Errorf("could not convert object to unstructured: %w", err)
+	}
+
+	spec, ok := content["spec"]
+	if !ok {
+		return "", nil
+	}
+
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return "", fmt.Errorf("could not marshal spec: %w", err)
+	}
+
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(data)), nil
+}
+
+// Entries returns the inventory entries by their ID.
+func Entries(inv *mpasv1alpha1.ResourceInventory) map[string]mpasv1alpha1.ResourceRef {
+	entries := make(map[string]mpasv1alpha1.ResourceRef)
+	if inv == nil {
+		return entries
+	}
+
+	for _, entry := range inv.Entries {
+		entries[entry.ID] = entry
+	}
+
+	return entries
+}
+
 // List return the inventory entries as unstructured.Unstructured objects.
 func List(inv *mpasv1alpha1.ResourceInventory) ([]*unstructured.Unstructured, error) {
 	objects := make([]*unstructured.Unstructured, 0)
@@ -87,16 +139,18 @@ func ListMetadata(inv *mpasv1alpha1.ResourceInventory) (object.ObjMetadataSet, e
 	return metas, nil
 }
 
-// Diff returns the slice of objects that do not exist in the target inventory.
-func Diff(source *mpasv1alpha1.ResourceInventory, target *mpasv1alpha1.ResourceInventory) ([]*unstructured.Unstructured, error) {
-	getVersion := func(inv *mpasv1alpha1.ResourceInventory, objMetadata object.ObjMetadata) string {
+// Diff returns the slice of objects that do not exist in the target inventory. The objects have the version
+// of their kind that is served by the cluster according to the given mapper, or the version of their entry
+// in the source inventory if the kind is no longer served or the mapper is nil.
+func Diff(source *mpasv1alpha1.ResourceInventory, target *mpasv1alpha1.ResourceInventory, mapper meta.RESTMapper) ([]*unstructured.Unstructured, error) {
+	getVersion := func(inv *mpasv1alpha1.ResourceInventory, objMetadata object.ObjMetadata) (string, error) {
 		for _, entry := range inv.Entries {
 			if entry.ID == objMetadata.String() {
-				return entry.Version
+				return servedVersion(mapper, objMetadata.GroupKind, entry.Version)
 			}
 		}
 
-		return ""
+		return "", nil
 	}
 
 	var objects []*unstructured.Unstructured
@@ -113,11 +167,16 @@ func Diff(source *mpasv1alpha1.ResourceInventory, target *mpasv1alpha1.ResourceI
 
 	list := aList.Diff(bList)
 	for _, metadata := range list {
+		version, err := getVersion(source, metadata)
+		if err != nil {
+			return nil, err
+		}
+
 		u := &unstructured.Unstructured{}
 		u.SetGroupVersionKind(schema.GroupVersionKind{
 			Group:   metadata.GroupKind.Group,
 			Kind:    metadata.GroupKind.Kind,
-			Version: getVersion(source, metadata),
+			Version: version,
 		})
 		u.SetName(metadata.Name)
 		u.SetNamespace(metadata.Namespace)
@@ -128,3 +187,181 @@ func Diff(source *mpasv1alpha1.ResourceInventory, target *mpasv1alpha1.ResourceI
 
 	return objects, nil
 }
+
+// MigrateVersions sets the version of the inventory entries to the preferred version of their kind that is
+// served by the cluster, so that stale objects can still be found after their kind moved to a new API version.
+// Entries of kinds that are no longer served keep their version. It returns the migrations in the format
+// '<kind>.<group> <old version> -> <new version>'.
+func MigrateVersions(inv *mpasv1alpha1.ResourceInventory, mapper meta.RESTMapper) ([]string, error) {
+	if inv == nil {
+		return nil, nil
+	}
+
+	var migrations []string
+	seen := make(map[string]bool)
+	for i, entry := range inv.Entries {
+		objMetadata, err := object.ParseObjMetadata(entry.ID)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse object metadata: %w", err)
+		}
+
+		version, err := servedVersion(mapper, objMetadata.GroupKind, entry.Version)
+		if err != nil {
+			return nil, err
+		}
+
+		if version == entry.Version {
+			continue
+		}
+
+		migration := fmt.Sprintf("%s %s -> %s", objMetadata.GroupKind, entry.Version, version)
+		if !seen[migration] {
+			seen[migration] = true
+			migrations = append(migrations, migration)
+		}
+
+		inv.Entries[i].Version = version
+	}
+
+	sort.Strings(migrations)
+
+	return migrations, nil
+}
+
+// servedVersion returns the preferred version of the kind that is served by the cluster, or the given version
+// if the kind is no longer served or the mapper is nil.
+func servedVersion(mapper meta.RESTMapper, gk schema.GroupKind, version string) (string, error) {
+	if mapper == nil {
+		return version, nil
+	}
+
+	mapping, err := mapper.RESTMapping(gk)
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			return version, nil
+		}
+
+		return "", fmt.Errorf("could not get preferred version of %s: %w", gk, err)
+	}
+
+	return mapping.GroupVersionKind.Version, nil
+}
+
+// FindByGroupKind returns the metadata of the inventory entries of the given group and kind.
+func FindByGroupKind(inv *mpasv1alpha1.ResourceInventory, gk schema.GroupKind) (object.ObjMetadataSet, error) {
+	metas, err := ListMetadata(inv)
+	if err != nil {
+		return nil, err
+	}
+
+	var found object.ObjMetadataSet
+	for _, m := range metas {
+		if m.GroupKind == gk {
+			found = append(found, m)
+		}
+	}
+
+	return found, nil
+}
+
+// Contains returns true if the inventory has an entry for the given object.
+func Contains(inv *mpasv1alpha1.ResourceInventory, objMetadata object.ObjMetadata) bool {
+	id := objMetadata.String()
+	for _, entry := range inv.Entries {
+		if entry.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Merge returns a new inventory with the entries of all given inventories. If an object is part of
+// multiple inventories, the version of the last one is kept.
+func Merge(invs ...*mpasv1alpha1.ResourceInventory) *mpasv1alpha1.ResourceInventory {
+	merged := New()
+	index := make(map[string]int)
+	for _, inv := range invs {
+		if inv == nil {
+			continue
+		}
+
+		for _, entry := range inv.Entries {
+			if i, ok := index[entry.ID]; ok {
+				merged.Entries[i] = entry
+
+				continue
+			}
+
+			index[entry.ID] = len(merged.Entries)
+			merged.Entries = append(merged.Entries, entry)
+		}
+	}
+
+	return merged
+}
+
+// Filter returns a new inventory with the entries for which keep returns true.
+func Filter(inv *mpasv1alpha1.ResourceInventory, keep func(object.ObjMetadata) bool) (*mpasv1alpha1.ResourceInventory, error) {
+	filtered := New()
+	for _, entry := range inv.Entries {
+		objMetadata, err := object.ParseObjMetadata(entry.ID)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse object metadata: %w", err)
+		}
+
+		if keep(objMetadata) {
+			filtered.Entries = append(filtered.Entries, entry)
+		}
+	}
+
+	return filtered, nil
+}
+
+// ServiceAccount returns the name of the project ServiceAccount in the inventory.
+func ServiceAccount(inv *mpasv1alpha1.ResourceInventory) (types.NamespacedName, error) {
+	objMetadata, err := findOne(inv, schema.GroupKind{Kind: "ServiceAccount"})
+	if err != nil {
+		return types.NamespacedName{}, err
+	}
+
+	return types.NamespacedName{Name: objMetadata.Name, Namespace: objMetadata.Namespace}, nil
+}
+
+// Namespace returns the name of the project namespace in the inventory.
+func Namespace(inv *mpasv1alpha1.ResourceInventory) (string, error) {
+	objMetadata, err := findOne(inv, schema.GroupKind{Kind: "Namespace"})
+	if err != nil {
+		return "", err
+	}
+
+	return objMetadata.Name, nil
+}
+
+// Repository returns the name of the git-controller Repository in the inventory.
+func Repository(inv *mpasv1alpha1.ResourceInventory) (types.NamespacedName, error) {
+	objMetadata, err := findOne(inv, schema.GroupKind{Group: mpasv1alpha1.GroupVersion.Group, Kind: "Repository"})
+	if err != nil {
+		return types.NamespacedName{}, err
+	}
+
+	return types.NamespacedName{Name: objMetadata.Name, Namespace: objMetadata.Namespace}, nil
+}
+
+// findOne returns the first inventory entry of the given group and kind.
+func findOne(inv *mpasv1alpha1.ResourceInventory, gk schema.GroupKind) (object.ObjMetadata, error) {
+	if inv == nil {
+		return object.ObjMetadata{}, fmt.Errorf("project inventory is empty")
+	}
+
+	found, err := FindByGroupKind(inv, gk)
+	if err != nil {
+		return object.ObjMetadata{}, err
+	}
+
+	if len(found) == 0 {
+		return object.ObjMetadata{}, fmt.Errorf("%s not found in the project inventory", strings.ToLower(gk.Kind))
+	}
+
+	return found[0], nil
+}
@@ -0,0 +1,155 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Open Component Model contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/cli-utils/pkg/object"
+
+	mpasv1alpha1 "github.com/open-component-model/mpas-project-controller/api/v1alpha1"
+)
+
+func testInventory() *mpasv1alpha1.ResourceInventory {
+	return &mpasv1alpha1.ResourceInventory{
+		Entries: []mpasv1alpha1.ResourceRef{
+			{ID: "_mpas-test-project__Namespace", Version: "v1"},
+			{ID: "mpas-test-project_mpas-test-project__ServiceAccount", Version: "v1"},
+			{ID: "mpas-system_mpas-test-project_mpas.ocm.software_Repository", Version: "v1alpha1"},
+			{ID: "mpas-system_mpas-test-project-targets_kustomize.toolkit.fluxcd.io_Kustomization", Version: "v1"},
+			{ID: "mpas-system_mpas-test-project-products_kustomize.toolkit.fluxcd.io_Kustomization", Version: "v1"},
+		},
+	}
+}
+
+func TestFindByGroupKind(t *testing.T) {
+	found, err := FindByGroupKind(testInventory(), schema.GroupKind{Group: "kustomize.toolkit.fluxcd.io", Kind: "Kustomization"})
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	assert.Equal(t, "mpas-test-project-targets", found[0].Name)
+	assert.Equal(t, "mpas-test-project-products", found[1].Name)
+
+	found, err = FindByGroupKind(testInventory(), schema.GroupKind{Kind: "Secret"})
+	require.NoError(t, err)
+	assert.Empty(t, found)
+}
+
+func TestContains(t *testing.T) {
+	assert.True(t, Contains(testInventory(), object.ObjMetadata{
+		Namespace: "mpas-system",
+		Name:      "mpas-test-project",
+		GroupKind: schema.GroupKind{Group: "mpas.ocm.software", Kind: "Repository"},
+	}))
+	assert.False(t, Contains(testInventory(), object.ObjMetadata{
+		Namespace: "mpas-system",
+		Name:      "mpas-test-project",
+		GroupKind: schema.GroupKind{Group: "source.toolkit.fluxcd.io", Kind: "GitRepository"},
+	}))
+}
+
+func TestMerge(t *testing.T) {
+	other := &mpasv1alpha1.ResourceInventory{
+		Entries: []mpasv1alpha1.ResourceRef{
+			{ID: "mpas-system_mpas-test-project_mpas.ocm.software_Repository", Version: "v1alpha2"},
+			{ID: "mpas-system_mpas-test-project_source.toolkit.fluxcd.io_GitRepository", Version: "v1"},
+		},
+	}
+
+	merged := Merge(testInventory(), nil, other)
+	require.Len(t, merged.Entries, 6)
+	assert.Equal(t, mpasv1alpha1.ResourceRef{ID: "mpas-system_mpas-test-project_mpas.ocm.software_Repository", Version: "v1alpha2"}, merged.Entries[2])
+	assert.Equal(t, "mpas-system_mpas-test-project_source.toolkit.fluxcd.io_GitRepository", merged.Entries[5].ID)
+}
+
+func TestFilter(t *testing.T) {
+	filtered, err := Filter(testInventory(), func(m object.ObjMetadata) bool {
+		return m.Namespace == "mpas-system"
+	})
+	require.NoError(t, err)
+	require.Len(t, filtered.Entries, 3)
+	assert.Equal(t, "mpas-system_mpas-test-project_mpas.ocm.software_Repository", filtered.Entries[0].ID)
+}
+
+func TestLookups(t *testing.T) {
+	sa, err := ServiceAccount(testInventory())
+	require.NoError(t, err)
+	assert.Equal(t, types.NamespacedName{Namespace: "mpas-test-project", Name: "mpas-test-project"}, sa)
+
+	namespace, err := Namespace(testInventory())
+	require.NoError(t, err)
+	assert.Equal(t, "mpas-test-project", namespace)
+
+	repository, err := Repository(testInventory())
+	require.NoError(t, err)
+	assert.Equal(t, types.NamespacedName{Namespace: "mpas-system", Name: "mpas-test-project"}, repository)
+
+	_, err = ServiceAccount(New())
+	assert.EqualError(t, err, "serviceaccount not found in the project inventory")
+
+	_, err = Namespace(nil)
+	assert.EqualError(t, err, "project inventory is empty")
+}
+
+func TestMigrateVersions(t *testing.T) {
+	kustomizationKind := schema.GroupVersionKind{Group: "kustomize.toolkit.fluxcd.io", Version: "v1", Kind: "Kustomization"}
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{kustomizationKind.GroupVersion()})
+	mapper.Add(kustomizationKind, meta.RESTScopeNamespace)
+
+	inv := testInventory()
+	inv.Entries[3].Version = "v1beta2"
+	inv.Entries[4].Version = "v1beta2"
+
+	migrations, err := MigrateVersions(inv, mapper)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Kustomization.kustomize.toolkit.fluxcd.io v1beta2 -> v1"}, migrations)
+	assert.Equal(t, "v1", inv.Entries[3].Version)
+	assert.Equal(t, "v1", inv.Entries[4].Version)
+
+	// Kinds that are not served keep their version.
+	assert.Equal(t, "v1alpha1", inv.Entries[2].Version)
+
+	migrations, err = MigrateVersions(inv, mapper)
+	require.NoError(t, err)
+	assert.Empty(t, migrations)
+}
+
+func TestDiff(t *testing.T) {
+	kustomizationKind := schema.GroupVersionKind{Group: "kustomize.toolkit.fluxcd.io", Version: "v1", Kind: "Kustomization"}
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{kustomizationKind.GroupVersion()})
+	mapper.Add(kustomizationKind, meta.RESTScopeNamespace)
+
+	source := testInventory()
+	source.Entries[2].Version = "v1alpha1"
+	source.Entries[3].Version = "v1beta2"
+	target := testInventory()
+	target.Entries = target.Entries[:2]
+
+	// Stale objects get the served version of their kind, or the version of their entry if it isn't served.
+	objects, err := Diff(source, target, mapper)
+	require.NoError(t, err)
+	require.Len(t, objects, 3)
+	versions := make(map[string]string)
+	for _, o := range objects {
+		versions[o.GetName()] = o.GetAPIVersion()
+	}
+	assert.Equal(t, map[string]string{
+		"mpas-test-project":          "mpas.ocm.software/v1alpha1",
+		"mpas-test-project-targets":  "kustomize.toolkit.fluxcd.io/v1",
+		"mpas-test-project-products": "kustomize.toolkit.fluxcd.io/v1",
+	}, versions)
+
+	objects, err = Diff(source, target, nil)
+	require.NoError(t, err)
+	for _, o := range objects {
+		if o.GetName() == "mpas-test-project-targets" {
+			assert.Equal(t, "kustomize.toolkit.fluxcd.io/v1beta2", o.GetAPIVersion())
+		}
+	}
+}
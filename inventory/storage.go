@@ -0,0 +1,251 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Open Component Model contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	mpasv1alpha1 "github.com/open-component-model/mpas-project-controller/api/v1alpha1"
+)
+
+const (
+	// configMapDataKey is the key of the compressed inventory entries in the ConfigMap.
+	configMapDataKey = "inventory.json.gz"
+	// configMapDigestLength is the number of characters of the digest of the entries in the name of
+	// the ConfigMap.
+	configMapDigestLength = 16
+)
+
+// ErrInvalidConfigMap is returned by Load if the inventory ConfigMap referenced by the status of the
+// Project doesn't exist or doesn't contain the referenced entries.
+var ErrInvalidConfigMap = errors.New("invalid inventory config map")
+
+// Storage keeps the inventory of a Project in its status. Inventories whose encoded entries are larger
+// than Threshold are stored compressed in a ConfigMap next to the Project instead, and the status only
+// contains a reference with the digest and the number of entries. The name of the ConfigMap contains the
+// digest, so the ConfigMap referenced by the status stays intact until the reference to a new ConfigMap
+// has been recorded.
+type Storage struct {
+	Client client.Client
+	// Reader reads the ConfigMaps from the API server, since the cache may not contain a ConfigMap that was
+	// just written. If nil, the Client is used.
+	Reader client.Reader
+	// Threshold is the size in bytes of the encoded inventory entries above which they are stored in
+	// a ConfigMap. Inventories are always stored in the status if Threshold is zero.
+	Threshold int
+}
+
+// ConfigMapName returns the name of the ConfigMap that stores the inventory entries with the given digest
+// of the given Project.
+func ConfigMapName(project *mpasv1alpha1.Project, digest string) string {
+	hash := strings.TrimPrefix(digest, "sha256:")
+	if len(hash) > configMapDigestLength {
+		hash = hash[:configMapDigestLength]
+	}
+
+	return configMapPrefix(project) + hash
+}
+
+func configMapPrefix(project *mpasv1alpha1.Project) string {
+	return project.Name + "-inventory-"
+}
+
+// Load returns the inventory of the Project with all of its entries. The entries of an inventory stored
+// in a ConfigMap are read from it and verified against the digest in the status. The reference to the
+// ConfigMap is kept in the returned inventory. An error that wraps ErrInvalidConfigMap is returned if the
+// ConfigMap is missing or doesn't match the reference.
+func (s Storage) Load(ctx context.Context, project *mpasv1alpha1.Project) (*mpasv1alpha1.ResourceInventory, error) {
+	inv := project.Status.Inventory
+	if inv == nil || inv.ConfigMapRef == nil {
+		return inv, nil
+	}
+
+	ref := inv.ConfigMapRef
+	cm := &corev1.ConfigMap{}
+	if err := s.reader().Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: project.Namespace}, cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: %s not found", ErrInvalidConfigMap, ref.Name)
+		}
+
+		return nil, fmt.Errorf("failed to get inventory config map %s: %w", ref.Name, err)
+	}
+
+	data, err := decompress(cm.BinaryData[configMapDataKey])
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read %s: %w", ErrInvalidConfigMap, ref.Name, err)
+	}
+
+	if d := digest(data); d != ref.Digest {
+		return nil, fmt.Errorf("%w: %s has digest %s, expected %s", ErrInvalidConfigMap, ref.Name, d, ref.Digest)
+	}
+
+	var entries []mpasv1alpha1.ResourceRef
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("%w: failed to decode %s: %w", ErrInvalidConfigMap, ref.Name, err)
+	}
+
+	if len(entries) != ref.Count {
+		return nil, fmt.Errorf("%w: %s has %d entries, expected %d", ErrInvalidConfigMap, ref.Name, len(entries), ref.Count)
+	}
+
+	return &mpasv1alpha1.ResourceInventory{
+		Entries:      entries,
+		ConfigMapRef: ref.DeepCopy(),
+	}, nil
+}
+
+// Save returns the inventory to record in the status of the Project. If the encoded entries are larger
+// than the threshold, they are written to the inventory ConfigMap of their digest first, unless the
+// reference of the given inventory shows that it is already up-to-date. Save doesn't delete the ConfigMaps
+// of previous inventories, call DeleteStale once the status has been updated.
+func (s Storage) Save(ctx context.Context, project *mpasv1alpha1.Project, inv *mpasv1alpha1.ResourceInventory) (*mpasv1alpha1.ResourceInventory, error) {
+	if inv == nil {
+		return nil, nil
+	}
+
+	entries := inv.Entries
+	if entries == nil {
+		entries = []mpasv1alpha1.ResourceRef{}
+	}
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode inventory: %w", err)
+	}
+
+	if s.Threshold <= 0 || len(data) <= s.Threshold {
+		return &mpasv1alpha1.ResourceInventory{Entries: entries}, nil
+	}
+
+	d := digest(data)
+	ref := &mpasv1alpha1.InventoryConfigMapReference{
+		Name:   ConfigMapName(project, d),
+		Digest: d,
+		Count:  len(entries),
+	}
+
+	if inv.ConfigMapRef == nil || *inv.ConfigMapRef != *ref {
+		if err := s.writeConfigMap(ctx, project, ref.Name, data); err != nil {
+			return nil, err
+		}
+	}
+
+	return &mpasv1alpha1.ResourceInventory{
+		Entries:      []mpasv1alpha1.ResourceRef{},
+		ConfigMapRef: ref,
+	}, nil
+}
+
+// DeleteStale deletes the inventory ConfigMaps of the Project except for the one referenced by the given
+// inventory.
+func (s Storage) DeleteStale(ctx context.Context, project *mpasv1alpha1.Project, inv *mpasv1alpha1.ResourceInventory) error {
+	configMaps := &corev1.ConfigMapList{}
+	if err := s.reader().List(ctx, configMaps, client.InNamespace(project.Namespace), client.MatchingLabels{
+		mpasv1alpha1.ProjectKey:          project.Name,
+		mpasv1alpha1.ProjectNamespaceKey: project.Namespace,
+	}); err != nil {
+		return fmt.Errorf("failed to list inventory config maps: %w", err)
+	}
+
+	var retErr error
+	for i := range configMaps.Items {
+		cm := &configMaps.Items[i]
+		if !strings.HasPrefix(cm.Name, configMapPrefix(project)) || !metav1.IsControlledBy(cm, project) {
+			continue
+		}
+
+		if inv != nil && inv.ConfigMapRef != nil && inv.ConfigMapRef.Name == cm.Name {
+			continue
+		}
+
+		if err := s.Client.Delete(ctx, cm); client.IgnoreNotFound(err) != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("failed to delete inventory config map %s: %w", cm.Name, err))
+		}
+	}
+
+	return retErr
+}
+
+func (s Storage) reader() client.Reader {
+	if s.Reader == nil {
+		return s.Client
+	}
+
+	return s.Reader
+}
+
+func (s Storage) writeConfigMap(ctx context.Context, project *mpasv1alpha1.Project, name string, data []byte) error {
+	compressed, err := compress(data)
+	if err != nil {
+		return fmt.Errorf("failed to compress inventory: %w", err)
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: project.Namespace,
+		},
+	}
+
+	_, err = controllerutil.CreateOrUpdate(ctx, s.Client, cm, func() error {
+		if cm.Labels == nil {
+			cm.Labels = make(map[string]string)
+		}
+
+		cm.Labels[mpasv1alpha1.ProjectKey] = project.Name
+		cm.Labels[mpasv1alpha1.ProjectNamespaceKey] = project.Namespace
+		cm.Data = nil
+		cm.BinaryData = map[string][]byte{configMapDataKey: compressed}
+
+		return controllerutil.SetControllerReference(project, cm, s.Client.Scheme())
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write inventory config map %s: %w", name, err)
+	}
+
+	return nil
+}
+
+func digest(data []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+}
+
+func compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
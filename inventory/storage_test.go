@@ -0,0 +1,108 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Open Component Model contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mpasv1alpha1 "github.com/open-component-model/mpas-project-controller/api/v1alpha1"
+)
+
+func TestStorage(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, mpasv1alpha1.AddToScheme(scheme))
+
+	project := &mpasv1alpha1.Project{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-project",
+			Namespace: "mpas-system",
+			UID:       "test-uid",
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	storage := Storage{Client: c, Threshold: 1}
+	ctx := context.Background()
+
+	// Small inventories are kept in the status.
+	stored, err := Storage{Client: c}.Save(ctx, project, testInventory())
+	require.NoError(t, err)
+	assert.Nil(t, stored.ConfigMapRef)
+	assert.Equal(t, testInventory().Entries, stored.Entries)
+
+	stored, err = storage.Save(ctx, project, testInventory())
+	require.NoError(t, err)
+	require.NotNil(t, stored.ConfigMapRef)
+	assert.Empty(t, stored.Entries)
+	assert.Equal(t, ConfigMapName(project, stored.ConfigMapRef.Digest), stored.ConfigMapRef.Name)
+	assert.Regexp(t, "^test-project-inventory-[0-9a-f]{16}$", stored.ConfigMapRef.Name)
+	assert.Equal(t, len(testInventory().Entries), stored.ConfigMapRef.Count)
+
+	project.Status.Inventory = stored
+	loaded, err := storage.Load(ctx, project)
+	require.NoError(t, err)
+	assert.Equal(t, testInventory().Entries, loaded.Entries)
+	assert.Equal(t, stored.ConfigMapRef, loaded.ConfigMapRef)
+
+	// The digest in the status must match the content of the ConfigMap.
+	ref := *stored.ConfigMapRef
+	project.Status.Inventory.ConfigMapRef.Digest = "sha256:invalid"
+	_, err = storage.Load(ctx, project)
+	assert.ErrorContains(t, err, "expected sha256:invalid")
+	assert.True(t, errors.Is(err, ErrInvalidConfigMap))
+
+	project.Status.Inventory.ConfigMapRef = &mpasv1alpha1.InventoryConfigMapReference{Name: "test-project-inventory-missing"}
+	_, err = storage.Load(ctx, project)
+	assert.True(t, errors.Is(err, ErrInvalidConfigMap))
+
+	// Changed entries are written to a new ConfigMap, the previous one is kept until it is deleted as stale.
+	inv := testInventory()
+	inv.Entries = inv.Entries[1:]
+	inv.ConfigMapRef = &ref
+	changed, err := storage.Save(ctx, project, inv)
+	require.NoError(t, err)
+	assert.NotEqual(t, ref.Name, changed.ConfigMapRef.Name)
+
+	project.Status.Inventory = &mpasv1alpha1.ResourceInventory{ConfigMapRef: &ref}
+	loaded, err = storage.Load(ctx, project)
+	require.NoError(t, err)
+	assert.Equal(t, testInventory().Entries, loaded.Entries)
+
+	// ConfigMaps that aren't owned by the project are kept.
+	foreign := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-project-inventory-foreign",
+			Namespace: "mpas-system",
+			Labels: map[string]string{
+				mpasv1alpha1.ProjectKey:          project.Name,
+				mpasv1alpha1.ProjectNamespaceKey: project.Namespace,
+			},
+		},
+	}
+	require.NoError(t, c.Create(ctx, foreign))
+
+	require.NoError(t, storage.DeleteStale(ctx, project, changed))
+	cm := &corev1.ConfigMap{}
+	err = c.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: "mpas-system"}, cm)
+	assert.True(t, apierrors.IsNotFound(err))
+	assert.NoError(t, c.Get(ctx, client.ObjectKey{Name: changed.ConfigMapRef.Name, Namespace: "mpas-system"}, cm))
+	assert.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(foreign), cm))
+
+	require.NoError(t, storage.DeleteStale(ctx, project, nil))
+	err = c.Get(ctx, client.ObjectKey{Name: changed.ConfigMapRef.Name, Namespace: "mpas-system"}, cm)
+	assert.True(t, apierrors.IsNotFound(err))
+}
@@ -7,6 +7,7 @@ package main
 import (
 	"flag"
 	"os"
+	"strings"
 
 	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
@@ -17,6 +18,8 @@ import (
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
@@ -44,6 +47,25 @@ func init() {
 	//+kubebuilder:scaffold:scheme
 }
 
+// defaultInventoryThreshold is the default size in bytes of an inventory above which it is stored in a ConfigMap.
+const defaultInventoryThreshold = 64 * 1024
+
+// defaultProjectRoleResources are the resources the project Role grants access to by default. API groups
+// are not granted as a whole, so that CRDs installed later on aren't writable by project ServiceAccounts.
+var defaultProjectRoleResources = []string{
+	"mpas.ocm.software/repositories",
+	"mpas.ocm.software/targets",
+	"mpas.ocm.software/productdeployments",
+	"mpas.ocm.software/productdeploymentgenerators",
+	"mpas.ocm.software/productdeploymentpipelines",
+	"delivery.ocm.software/componentsubscriptions",
+	"delivery.ocm.software/componentversions",
+	"delivery.ocm.software/localizations",
+	"delivery.ocm.software/configurations",
+	"source.toolkit.fluxcd.io/ocirepositories",
+	"kustomize.toolkit.fluxcd.io/kustomizations",
+}
+
 func main() {
 	var (
 		metricsAddr           string
@@ -57,6 +79,12 @@ func main() {
 		defaultNamespace      string
 		registryAddress       string
 		certificateIssuerName string
+		allowedRBACAPIGroups  string
+		allowedRBACVerbs      string
+		allowedRBACResources  string
+		projectRoleResources  string
+		deletionBlockingKinds string
+		inventoryThreshold    int
 	)
 
 	flag.StringVar(
@@ -69,7 +97,52 @@ func main() {
 		&registryAddress,
 		"registry-address",
 		"registry.ocm-system.svc.cluster.local",
-		"The address of the internal registry. This is used for the certificate DNS names.",
+		"The in-cluster service address of the internal registry, e.g. <service>.<namespace>.svc.cluster.local. "+
+			"This is used for the certificate DNS names and the registry NetworkPolicy of project namespaces.",
+	)
+	flag.StringVar(
+		&allowedRBACAPIGroups,
+		"allowed-rbac-api-groups",
+		"",
+		"Comma separated list of API groups that additional Project RBAC rules may refer to. Use \"*\" to allow all groups.",
+	)
+	flag.StringVar(
+		&allowedRBACVerbs,
+		"allowed-rbac-verbs",
+		"get,list,watch",
+		"Comma separated list of verbs that additional Project RBAC rules may grant. Use \"*\" to allow all verbs.",
+	)
+	flag.StringVar(
+		&allowedRBACResources,
+		"allowed-rbac-resources",
+		"",
+		"Comma separated list of resources that additional Project RBAC rules may refer to. "+
+			"Use \"*\" to allow all named resources, rules granting the \"*\" resource are always rejected. "+
+			"The controller must be granted the permitted rules itself, since it can't grant permissions it doesn't hold.",
+	)
+	flag.StringVar(
+		&projectRoleResources,
+		"project-role-resources",
+		strings.Join(defaultProjectRoleResources, ","),
+		"Comma separated list of <group>/<resource> entries. "+
+			"The project Role grants access to the matching namespaced resources that are installed in the cluster. "+
+			"A <group>/* entry grants access to all resources of the group, including those installed later on. "+
+			"The controller must be granted the selected resources itself.",
+	)
+	flag.StringVar(
+		&deletionBlockingKinds,
+		"deletion-blocking-kinds",
+		"mpas.ocm.software/ProductDeployment",
+		"Comma separated list of <group>/<kind> entries. Projects that opt in with spec.deletionPolicy.blockWhileInUse "+
+			"are not deleted while resources of these kinds exist in the project namespace. The controller needs "+
+			"permission to list these kinds in the project namespaces.",
+	)
+	flag.IntVar(
+		&inventoryThreshold,
+		"inventory-configmap-threshold",
+		defaultInventoryThreshold,
+		"The size in bytes of the encoded Project inventory above which it is stored compressed in a ConfigMap "+
+			"next to the Project instead of its status. Set to 0 to always store the inventory in the status.",
 	)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -146,8 +219,24 @@ func main() {
 		os.Exit(1)
 	}
 
+	roleResources := splitList(projectRoleResources)
+	for _, entry := range roleResources {
+		if !strings.Contains(entry, "/") {
+			setupLog.Error(nil, "project role resources must be in the format <group>/<resource> or <group>/*", "entry", entry)
+			os.Exit(1)
+		}
+	}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to create discovery client")
+		os.Exit(1)
+	}
+
 	if err = (&controllers.ProjectReconciler{
 		Client:          mgr.GetClient(),
+		APIReader:       mgr.GetAPIReader(),
+		EventRecorder:   mgr.GetEventRecorderFor("project-controller"),
 		Scheme:          mgr.GetScheme(),
 		ClusterRoleName: clusterRoleName,
 		Prefix:          prefix,
@@ -158,7 +247,14 @@ func main() {
 			Email:   defaultCommitEmail,
 			Message: defaultCommitMessage,
 		},
-		DefaultNamespace: defaultNamespace,
+		DefaultNamespace:      defaultNamespace,
+		AllowedRBACAPIGroups:  splitList(allowedRBACAPIGroups),
+		AllowedRBACVerbs:      splitList(allowedRBACVerbs),
+		AllowedRBACResources:  splitList(allowedRBACResources),
+		DiscoveryClient:       memory.NewMemCacheClient(discoveryClient),
+		ProjectRoleResources:  roleResources,
+		DeletionBlockingKinds: splitList(deletionBlockingKinds),
+		InventoryThreshold:    inventoryThreshold,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Project")
 		os.Exit(1)
@@ -190,3 +286,15 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// splitList splits a comma separated flag value into its non-empty elements.
+func splitList(value string) []string {
+	var result []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			result = append(result, v)
+		}
+	}
+
+	return result
+}